@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxAliasDepth limits how deeply aliases may expand into other aliases, so
+// an alias that refers to itself fails instead of recursing forever.
+const maxAliasDepth = 10
+
+// userAliases are the aliases and macros defined with the alias command,
+// persisted to a file in the config directory. Each one maps a name to one or
+// more commands separated by ";", which may refer to the alias arguments as
+// $1 to $9 or $@ for all of them.
+type userAliases struct {
+	path    string
+	entries map[string]string
+}
+
+// loadAliases reads the aliases stored at path, one
+// `alias name = "expansion"` definition per line. A missing file is not an
+// error, and an empty path keeps the aliases in memory only.
+func loadAliases(path string) (*userAliases, error) {
+	a := &userAliases{
+		path:    path,
+		entries: map[string]string{},
+	}
+	if path == "" {
+		return a, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return a, nil
+	}
+	if err != nil {
+		return a, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		tokens, err := tokenize(line)
+		if err == nil && (len(tokens) == 0 || tokens[0] != "alias") {
+			err = fmt.Errorf("expected an alias definition")
+		}
+		var name, expansion string
+		if err == nil {
+			name, expansion, err = parseAliasDefinition(tokens[1:])
+		}
+		if err != nil {
+			return a, fmt.Errorf("%s line %d: %w", path, lineNumber, err)
+		}
+		a.entries[name] = expansion
+	}
+
+	return a, scanner.Err()
+}
+
+// Get returns the expansion of the named alias.
+func (a *userAliases) Get(name string) (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	expansion, ok := a.entries[name]
+	return expansion, ok
+}
+
+// Names returns the alias names in alphabetical order.
+func (a *userAliases) Names() []string {
+	if a == nil {
+		return nil
+	}
+	names := make([]string, 0, len(a.entries))
+	for name := range a.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Set defines or replaces an alias and saves the aliases.
+func (a *userAliases) Set(name, expansion string) error {
+	a.entries[name] = expansion
+	return a.save()
+}
+
+// Remove deletes an alias and saves the aliases.
+func (a *userAliases) Remove(name string) error {
+	if _, ok := a.entries[name]; !ok {
+		return fmt.Errorf("no alias named %q", name)
+	}
+	delete(a.entries, name)
+	return a.save()
+}
+
+func (a *userAliases) save() error {
+	if a.path == "" {
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(a.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	var content strings.Builder
+	for _, name := range a.Names() {
+		fmt.Fprintf(&content, "alias %s = %s\n", name, quoteArg(a.entries[name]))
+	}
+	return os.WriteFile(a.path, []byte(content.String()), 0o600)
+}
+
+// parseAliasDefinition reads the name and expansion from the arguments of an
+// alias definition, accepting `name = expansion`, `name expansion` and
+// `name=expansion`.
+func parseAliasDefinition(args []string) (string, string, error) {
+	if len(args) == 1 {
+		name, expansion, ok := strings.Cut(args[0], "=")
+		if !ok {
+			return "", "", fmt.Errorf("alias %q needs an expansion", args[0])
+		}
+		args = []string{strings.TrimSpace(name), strings.TrimSpace(expansion)}
+	}
+	if len(args) == 3 && args[1] == "=" {
+		args = []string{args[0], args[2]}
+	}
+	if len(args) == 2 && args[1] == "=" {
+		return "", "", fmt.Errorf("alias %q needs an expansion", args[0])
+	}
+	if len(args) != 2 {
+		return "", "", fmt.Errorf(`an alias is defined as: alias name = "commands"`)
+	}
+
+	name := strings.ToLower(args[0])
+	expansion := strings.TrimSpace(args[1])
+	switch {
+	case name == "" || strings.ContainsAny(name, " \t=;$"):
+		return "", "", fmt.Errorf("invalid alias name %q", args[0])
+	case expansion == "":
+		return "", "", fmt.Errorf("alias %q needs an expansion", name)
+	}
+	return name, expansion, nil
+}
+
+// quoteArg quotes s so that tokenize reads it back as a single word.
+func quoteArg(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// expandAlias turns an alias expansion and the arguments it was called with
+// into the commands to run. $1 to $9 are replaced by the matching argument
+// and $@ by all of them; a placeholder standing alone for a missing argument
+// is dropped so commands fall back to their defaults. When the expansion uses
+// no placeholders, the arguments are appended to its last command.
+func expandAlias(expansion string, args []string) ([][]string, error) {
+	commands := [][]string{}
+	usesArgs := false
+
+	segments, err := tokenizeCommands(expansion)
+	if err != nil {
+		return nil, err
+	}
+	for _, tokens := range segments {
+		if len(tokens) == 0 {
+			continue
+		}
+
+		expanded := []string{}
+		for _, token := range tokens {
+			if token == "$@" {
+				usesArgs = true
+				expanded = append(expanded, args...)
+				continue
+			}
+			if n, ok := aliasPlaceholder(token); ok {
+				usesArgs = true
+				if n <= len(args) {
+					expanded = append(expanded, args[n-1])
+				}
+				continue
+			}
+			replaced, used := replacePlaceholders(token, args)
+			usesArgs = usesArgs || used
+			expanded = append(expanded, replaced)
+		}
+		commands = append(commands, expanded)
+	}
+
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("alias expands to no commands")
+	}
+	if !usesArgs {
+		last := len(commands) - 1
+		commands[last] = append(commands[last], args...)
+	}
+	return commands, nil
+}
+
+// aliasPlaceholder reports whether token is exactly $1 to $9, and which.
+func aliasPlaceholder(token string) (int, bool) {
+	if len(token) != 2 || token[0] != '$' || token[1] < '1' || token[1] > '9' {
+		return 0, false
+	}
+	return int(token[1] - '0'), true
+}
+
+// replacePlaceholders replaces $1 to $9 inside token, e.g. --nickname=$2.
+func replacePlaceholders(token string, args []string) (string, bool) {
+	used := false
+	for n := 9; n >= 1; n-- {
+		placeholder := "$" + strconv.Itoa(n)
+		if !strings.Contains(token, placeholder) {
+			continue
+		}
+		used = true
+		value := ""
+		if n <= len(args) {
+			value = args[n-1]
+		}
+		token = strings.ReplaceAll(token, placeholder, value)
+	}
+	return token, used
+}
+
+// configDir returns the directory for files the user edits to configure the
+// Pokedex, following the XDG base directory convention.
+func configDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "pokedex"), nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pokedex"), nil
+}
+
+type aliasesResult struct {
+	Aliases []aliasEntry `json:"aliases"`
+}
+
+type aliasEntry struct {
+	Name      string `json:"name"`
+	Expansion string `json:"expansion"`
+	BuiltIn   bool   `json:"built_in"`
+}
+
+func (r aliasesResult) writeText(w io.Writer) {
+	for _, alias := range r.Aliases {
+		if alias.BuiltIn {
+			fmt.Fprintf(w, "%s = %s (built-in)\n", alias.Name, alias.Expansion)
+		} else {
+			fmt.Fprintf(w, "%s = %s\n", alias.Name, quoteArg(alias.Expansion))
+		}
+	}
+}
+
+func (r aliasesResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, alias := range r.Aliases {
+		rows = append(rows, []string{alias.Name, alias.Expansion, strconv.FormatBool(alias.BuiltIn)})
+	}
+	return []string{"alias", "expansion", "built-in"}, rows
+}
+
+func commandAlias(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	if cfg.aliases == nil {
+		cfg.aliases, _ = loadAliases("")
+	}
+
+	if flags.has("remove") {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("you must provide the name of the alias to remove")
+		}
+		name := strings.ToLower(args[0])
+		if err := cfg.aliases.Remove(name); err != nil {
+			return nil, err
+		}
+		return messageResult{Message: fmt.Sprintf("Removed alias %s", name)}, nil
+	}
+
+	if len(args) == 0 {
+		return newAliasesResult(cfg), nil
+	}
+
+	name, expansion, err := parseAliasDefinition(args)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := lookupCommand(name); exists {
+		return nil, fmt.Errorf("%q is already a command", name)
+	}
+	if err := cfg.aliases.Set(name, expansion); err != nil {
+		return nil, err
+	}
+
+	return messageResult{Message: fmt.Sprintf("%s = %s", name, quoteArg(expansion))}, nil
+}
+
+func newAliasesResult(cfg *config) aliasesResult {
+	result := aliasesResult{Aliases: []aliasEntry{}}
+	for _, cmd := range getCommands() {
+		for _, alias := range cmd.aliases {
+			result.Aliases = append(result.Aliases, aliasEntry{Name: alias, Expansion: cmd.name, BuiltIn: true})
+		}
+	}
+	for _, name := range cfg.aliases.Names() {
+		expansion, _ := cfg.aliases.Get(name)
+		result.Aliases = append(result.Aliases, aliasEntry{Name: name, Expansion: expansion})
+	}
+
+	sort.SliceStable(result.Aliases, func(i, j int) bool {
+		return result.Aliases[i].Name < result.Aliases[j].Name
+	})
+	return result
+}
+
+// lookupCommand finds a command by its name or one of its built-in aliases.
+func lookupCommand(name string) (cliCommand, bool) {
+	commands := getCommands()
+	if cmd, ok := commands[name]; ok {
+		return cmd, true
+	}
+	for _, cmd := range commands {
+		for _, alias := range cmd.aliases {
+			if alias == name {
+				return cmd, true
+			}
+		}
+	}
+	return cliCommand{}, false
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// commandFlags holds the flags given to a command, keyed by name without the
+// leading dashes. Flags that take no value are stored as "true".
+type commandFlags map[string]string
+
+func (f commandFlags) has(name string) bool {
+	_, ok := f[name]
+	return ok
+}
+
+func (f commandFlags) get(name string) string {
+	return f[name]
+}
+
+// int returns the value of the named flag as a number. A flag that was not
+// given is 0.
+func (f commandFlags) int(name string) (int, error) {
+	value, ok := f[name]
+	if !ok {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("flag --%s needs a number, got %q", name, value)
+	}
+	return n, nil
+}
+
+// tokenize splits input into words the way a shell would: words are
+// separated by whitespace, single quotes keep everything literally, double
+// quotes allow \" and \\ escapes, and a backslash outside quotes escapes the
+// next character.
+func tokenize(input string) ([]string, error) {
+	commands, err := splitWords(input, false)
+	if err != nil {
+		return nil, err
+	}
+	return commands[0], nil
+}
+
+// tokenizeCommands splits input into commands separated by semicolons, and
+// each command into words as tokenize does. Quoted or escaped semicolons are
+// part of a word.
+func tokenizeCommands(input string) ([][]string, error) {
+	return splitWords(input, true)
+}
+
+// splitWords does the work of tokenize and tokenizeCommands, treating ; as a
+// command separator when separate is set.
+func splitWords(input string, separate bool) ([][]string, error) {
+	commands := [][]string{}
+	tokens := []string{}
+	var current strings.Builder
+	inToken := false
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			inToken = true
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ' quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+		case r == '"':
+			inToken = true
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+					i++
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated \" quote")
+			}
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("nothing to escape after \\")
+			}
+			inToken = true
+			i++
+			current.WriteRune(runes[i])
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		case r == ';' && separate:
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+			commands = append(commands, tokens)
+			tokens = []string{}
+		default:
+			inToken = true
+			current.WriteRune(r)
+		}
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+
+	return append(commands, tokens), nil
+}
+
+func indexRune(runes []rune, start int, target rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseArgs separates the flags declared by cmd from its positional
+// arguments. Flags take the forms --name, --name value and --name=value, and
+// a lone -- ends flag parsing. Positional arguments that cmd declares as
+// PokeAPI identifiers are normalized with apiName.
+func parseArgs(cmd cliCommand, tokens []string) (commandFlags, []string, error) {
+	flags := commandFlags{}
+	positional := []string{}
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token == "--" {
+			positional = append(positional, tokens[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(token, "--") {
+			positional = append(positional, token)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(token, "--"), "=")
+		spec, ok := cmd.flag(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown flag --%s for %s, usage: %s", name, cmd.name, commandUsage(cmd))
+		}
+
+		switch {
+		case spec.value == "" && hasValue:
+			return nil, nil, fmt.Errorf("flag --%s for %s does not take a value", name, cmd.name)
+		case spec.value == "":
+			value = "true"
+		case !hasValue:
+			if i+1 >= len(tokens) {
+				return nil, nil, fmt.Errorf("flag --%s for %s needs a value", name, cmd.name)
+			}
+			i++
+			value = tokens[i]
+		}
+		flags[name] = value
+	}
+
+	variadic := len(cmd.args) > 0 && cmd.args[len(cmd.args)-1].variadic
+	if len(positional) > len(cmd.args) && !variadic {
+		return nil, nil, fmt.Errorf("too many arguments for %s, usage: %s", cmd.name, commandUsage(cmd))
+	}
+
+	for i, arg := range cmd.args {
+		if i < len(positional) && arg.identifier {
+			positional[i] = apiName(positional[i])
+		}
+	}
+
+	return flags, positional, nil
+}
+
+// flag returns the declaration of the named flag.
+func (cmd cliCommand) flag(name string) (commandFlag, bool) {
+	for _, flag := range cmd.flags {
+		if flag.name == name {
+			return flag, true
+		}
+	}
+	return commandFlag{}, false
+}
+
+// apiName turns user input into the form PokeAPI uses for names:
+// lower case, with spaces replaced by hyphens.
+func apiName(s string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "-")
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/see-why/Pokedex/internal/ansi"
+	"github.com/see-why/Pokedex/internal/sprite"
+)
+
+// typeColors are the colors the games use for each Pokemon type.
+var typeColors = map[string]string{
+	"normal":   "#A8A77A",
+	"fire":     "#EE8130",
+	"water":    "#6390F0",
+	"electric": "#F7D02C",
+	"grass":    "#7AC74C",
+	"ice":      "#96D9D6",
+	"fighting": "#C22E28",
+	"poison":   "#A33EA1",
+	"ground":   "#E2BF65",
+	"flying":   "#A98FF3",
+	"psychic":  "#F95587",
+	"bug":      "#A6B91A",
+	"rock":     "#B6A136",
+	"ghost":    "#735797",
+	"dragon":   "#6F35FC",
+	"dark":     "#705746",
+	"steel":    "#B7B7CE",
+	"fairy":    "#D685AD",
+}
+
+// statBarWidth is how many cells the longest stat bar fills.
+const statBarWidth = 30
+
+// useColor reports whether output should be colored: always or never when
+// the color setting says so, and otherwise only when NO_COLOR is unset and
+// output goes to a terminal.
+func (cfg *config) useColor() bool {
+	switch cfg.currentSettings().Color {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := cfg.outWriter().(*os.File)
+	return ok && readline.IsTerminal(int(f.Fd()))
+}
+
+// spriteMaxWidth is the widest a sprite is drawn, in columns.
+const spriteMaxWidth = 64
+
+// renderSprite fetches the front sprite of pokemon through the cache and
+// draws it for the terminal: in truecolor when the terminal supports it, in
+// 256 colors otherwise, and as ASCII when colors are off.
+func renderSprite(cfg *config, pokemon Pokemon) (string, error) {
+	if pokemon.Sprites.FrontDefault == "" {
+		return "", fmt.Errorf("%s has no sprite", pokemon.Name)
+	}
+
+	dat, err := fetchBytes(cfg, pokemon.Sprites.FrontDefault, cfg.diagWriter())
+	if err != nil {
+		return "", err
+	}
+	img, err := sprite.Decode(bytes.NewReader(dat))
+	if err != nil {
+		return "", err
+	}
+
+	mode := sprite.ASCII
+	if cfg.useColor() {
+		mode = sprite.Color256
+		if trueColor() {
+			mode = sprite.TrueColor
+		}
+	}
+
+	var out strings.Builder
+	if err := sprite.Render(&out, img, mode, spriteMaxWidth); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// paint styles text with ansi styles. It only fails for unknown styles, which
+// are a programming error, so text is then left plain.
+func paint(text string, styles ...string) string {
+	painted, err := ansi.Paint(text, styles...)
+	if err != nil {
+		return text
+	}
+	return painted
+}
+
+// trueColor reports whether COLORTERM says the terminal supports 24-bit
+// color. Other terminals get the nearest of the xterm 256 colors.
+func trueColor() bool {
+	colorTerm := os.Getenv("COLORTERM")
+	return colorTerm == "truecolor" || colorTerm == "24bit"
+}
+
+// paintType colors a type name with its type color, in 24-bit color or the
+// nearest of the 256 colors as the terminal supports. Types without one, such
+// as "unknown", stay plain.
+func paintType(typeName string) string {
+	color, ok := typeColors[typeName]
+	if !ok {
+		return typeName
+	}
+	if !trueColor() {
+		color = ansi.Reduce(color)
+	}
+	return paint(typeName, "bold", color)
+}
+
+// statBar draws value as a bar of block characters, scaled so that maxValue
+// fills width cells. Eighth blocks keep short differences visible.
+func statBar(value, maxValue, width int) string {
+	if maxValue <= 0 || value <= 0 {
+		return ""
+	}
+
+	eighths := min(value, maxValue) * width * 8 / maxValue
+	bar := strings.Repeat("█", eighths/8)
+	if partial := eighths % 8; partial > 0 {
+		bar += string([]rune("▏▎▍▌▋▊▉")[partial-1])
+	}
+	return bar
+}
+
+// statColor picks a color for a base stat, from red for weak to cyan for
+// exceptional.
+func statColor(value int) string {
+	switch {
+	case value < 50:
+		return "red"
+	case value < 80:
+		return "yellow"
+	case value < 110:
+		return "green"
+	default:
+		return "cyan"
+	}
+}
+
+func (r inspectResult) writeColorText(w io.Writer) {
+	fmt.Fprint(w, r.sprite)
+
+	fmt.Fprintf(w, "Name: %s\n", paint(r.Name, "bold"))
+	if r.Nickname != "" {
+		fmt.Fprintf(w, "Nickname: %s\n", paint(r.Nickname, "bold"))
+	}
+	fmt.Fprintf(w, "Height: %d\n", r.Height)
+	fmt.Fprintf(w, "Weight: %d\n", r.Weight)
+	r.writeTraining(w)
+
+	nameWidth, maxStat := 0, 0
+	for _, stat := range r.Stats {
+		nameWidth = max(nameWidth, len(stat.Name))
+		maxStat = max(maxStat, stat.BaseStat)
+	}
+
+	fmt.Fprintln(w, "Stats:")
+	for _, stat := range r.Stats {
+		bar := paint(statBar(stat.BaseStat, maxStat, statBarWidth), statColor(stat.BaseStat))
+		fmt.Fprintf(w, "  %-*s %3d %s\n", nameWidth, stat.Name, stat.BaseStat, bar)
+	}
+
+	fmt.Fprintln(w, "Types:")
+	for _, typeName := range r.Types {
+		fmt.Fprintf(w, "  - %s\n", paintType(typeName))
+	}
+
+	r.writeHistory(w)
+	r.writeSpecies(w)
+}
+
+func (r catchResult) writeColorText(w io.Writer) {
+	fmt.Fprintf(w, "Throwing a Pokeball at %s...\n", r.Pokemon)
+	if r.Caught && r.Nickname != "" {
+		fmt.Fprintln(w, paint(fmt.Sprintf("%s was caught and named %s!", r.Pokemon, r.Nickname), "bold", "green"))
+		fmt.Fprintf(w, "You may now inspect it with the inspect command.\n")
+	} else if r.Caught {
+		fmt.Fprintln(w, paint(fmt.Sprintf("%s was caught!", r.Pokemon), "bold", "green"))
+		fmt.Fprintf(w, "You may now inspect it with the inspect command.\n")
+	} else {
+		fmt.Fprintln(w, paint(fmt.Sprintf("%s escaped!", r.Pokemon), "bold", "red"))
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/see-why/Pokedex/internal/typechart"
+)
+
+// statNames are the base stats in the order the games list them.
+var statNames = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+type compareResult struct {
+	Pokemon  []compareSide   `json:"pokemon"`
+	Stats    []compareStat   `json:"stats"`
+	Matchups []compareAttack `json:"matchups"`
+}
+
+type compareSide struct {
+	Name   string   `json:"name"`
+	Types  []string `json:"types"`
+	Height int      `json:"height"`
+	Weight int      `json:"weight"`
+	Total  int      `json:"total"`
+}
+
+// compareStat is one row of base stats, with a value for each Pokemon in the
+// order they were given. Best names the Pokemon with the highest value, and is
+// empty when they are all equal.
+type compareStat struct {
+	Name   string   `json:"name"`
+	Values []int    `json:"values"`
+	Best   []string `json:"best,omitempty"`
+}
+
+// compareAttack is the hardest hit one Pokemon's types land on another.
+type compareAttack struct {
+	Attacker string  `json:"attacker"`
+	Defender string  `json:"defender"`
+	Best     typeHit `json:"best"`
+}
+
+func (r compareResult) writeText(w io.Writer) {
+	r.write(w, func(value string) string { return value + "*" }, 1)
+}
+
+func (r compareResult) writeColorText(w io.Writer) {
+	r.write(w, func(value string) string { return paint(value, "bold", "green") }, 0)
+}
+
+// write lays the comparison out in columns, one per Pokemon, passing the
+// highest values of each stat row through highlight, which makes them
+// markerWidth columns wider on screen, at most one.
+func (r compareResult) write(w io.Writer, highlight func(string) string, markerWidth int) {
+	header := []string{""}
+	for _, side := range r.Pokemon {
+		header = append(header, side.Name)
+	}
+	rows := r.rows()
+
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			// A spare column fits the widest highlight marker
+			widths[i] = max(widths[i], len(cell)+1)
+		}
+	}
+
+	// Which columns of each stat row hold its highest value
+	best := map[string][]bool{}
+	for _, stat := range r.Stats {
+		if len(stat.Best) == 0 {
+			continue
+		}
+		highest := 0
+		for _, value := range stat.Values {
+			highest = max(highest, value)
+		}
+		for _, value := range stat.Values {
+			best[stat.Name] = append(best[stat.Name], value == highest)
+		}
+	}
+
+	for _, row := range append([][]string{header}, rows...) {
+		var line strings.Builder
+		for i, cell := range row {
+			visible := len(cell)
+			if i > 0 && i <= len(best[row[0]]) && best[row[0]][i-1] {
+				cell = highlight(cell)
+				visible += markerWidth
+			}
+			padding := strings.Repeat(" ", widths[i]-visible)
+			line.WriteString(cell + padding)
+			if i < len(row)-1 {
+				line.WriteString(" ")
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+	}
+
+	fmt.Fprintln(w, "Matchups:")
+	for _, attack := range r.Matchups {
+		fmt.Fprintf(w, "  %s attacking %s: %s (%s)\n", attack.Attacker, attack.Defender, typechart.Format(attack.Best.Multiplier), attack.Best.Type)
+	}
+}
+
+// rows are the types, size, base stats and stat total of each Pokemon, with
+// the row name first.
+func (r compareResult) rows() [][]string {
+	types := []string{"types"}
+	height := []string{"height"}
+	weight := []string{"weight"}
+	for _, side := range r.Pokemon {
+		types = append(types, strings.Join(side.Types, "/"))
+		height = append(height, strconv.Itoa(side.Height))
+		weight = append(weight, strconv.Itoa(side.Weight))
+	}
+
+	rows := [][]string{types, height, weight}
+	for _, stat := range r.Stats {
+		row := []string{stat.Name}
+		for _, value := range stat.Values {
+			row = append(row, strconv.Itoa(value))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (r compareResult) tableRows() ([]string, [][]string) {
+	header := []string{""}
+	for _, side := range r.Pokemon {
+		header = append(header, side.Name)
+	}
+	return header, r.rows()
+}
+
+func commandCompare(cfg *config, _ commandFlags, args ...string) (commandResult, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("compare at least two Pokemon, e.g. compare pikachu raichu")
+	}
+
+	result := compareResult{}
+	pokemon := []ownedPokemon{}
+	typeNames := []string{}
+	for _, name := range args {
+		p, err := findPokemon(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		side := compareSide{Name: p.displayName(), Types: []string{}, Height: p.Height, Weight: p.Weight, Total: totalStats(p)}
+		for _, t := range p.Types {
+			side.Types = append(side.Types, t.Type.Name)
+		}
+		pokemon = append(pokemon, p)
+		result.Pokemon = append(result.Pokemon, side)
+		typeNames = append(typeNames, side.Types...)
+	}
+
+	result.Stats = []compareStat{}
+	for _, name := range statNames {
+		stat := compareStat{Name: name}
+		known := false
+		for _, p := range pokemon {
+			value := baseStat(name)(p)
+			stat.Values = append(stat.Values, value)
+			known = known || hasStat(p, name)
+		}
+		// Leave out stats none of the Pokemon have data for
+		if known {
+			result.Stats = append(result.Stats, stat)
+		}
+	}
+	total := compareStat{Name: "total"}
+	for _, side := range result.Pokemon {
+		total.Values = append(total.Values, side.Total)
+	}
+	result.Stats = append(result.Stats, total)
+
+	for i := range result.Stats {
+		result.Stats[i].Best = bestOf(result.Pokemon, result.Stats[i].Values)
+	}
+
+	chart, err := getTypeChart(cfg, typeNames...)
+	if err != nil {
+		return nil, err
+	}
+	result.Matchups = []compareAttack{}
+	for i, attacker := range result.Pokemon {
+		for j, defender := range result.Pokemon {
+			if i == j {
+				continue
+			}
+			hits, err := typeHits(chart, attacker.Types, defender.Types)
+			if err != nil {
+				return nil, err
+			}
+			if len(hits) == 0 {
+				continue
+			}
+			attack := compareAttack{Attacker: attacker.Name, Defender: defender.Name, Best: hits[0]}
+			for _, hit := range hits[1:] {
+				if hit.Multiplier > attack.Best.Multiplier {
+					attack.Best = hit
+				}
+			}
+			result.Matchups = append(result.Matchups, attack)
+		}
+	}
+
+	return result, nil
+}
+
+func hasStat(pokemon ownedPokemon, name string) bool {
+	for _, stat := range pokemon.Stats {
+		if stat.Stat.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bestOf names the Pokemon with the highest of values, or none when the
+// values are all equal and there is nothing to highlight.
+func bestOf(pokemon []compareSide, values []int) []string {
+	highest, lowest := values[0], values[0]
+	for _, value := range values {
+		highest = max(highest, value)
+		lowest = min(lowest, value)
+	}
+	if highest == lowest {
+		return nil
+	}
+
+	best := []string{}
+	for i, value := range values {
+		if value == highest {
+			best = append(best, pokemon[i].Name)
+		}
+	}
+	return best
+}
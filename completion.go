@@ -0,0 +1,181 @@
+package main
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// replCompleter implements readline.AutoCompleter for the REPL. It completes
+// command names in the first position and the first argument of commands
+// that take a Pokemon or location area name.
+type replCompleter struct {
+	cfg *config
+	// nameIndexes memoizes the name indexes so completion only goes to the
+	// network the first time a kind of name is completed. They may be loaded
+	// in the background by prefetch, so mu guards them.
+	mu          sync.Mutex
+	nameIndexes map[string][]string
+}
+
+func newReplCompleter(cfg *config) *replCompleter {
+	return &replCompleter{
+		cfg:         cfg,
+		nameIndexes: make(map[string][]string),
+	}
+}
+
+// Do returns the suffixes that complete the word under the cursor, along with
+// the length of the part of the word that has already been typed. The line
+// is split into words as the REPL will run it, quotes and escapes included.
+func (c *replCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	words, quote, ok := completionWords(string(line[:pos]))
+	if !ok {
+		return nil, 0
+	}
+
+	current := []rune(words[len(words)-1])
+	var candidates []string
+	switch len(words) {
+	case 1:
+		candidates = c.commandNames()
+	case 2:
+		candidates = c.argumentNames(strings.ToLower(words[0]))
+	}
+
+	// Matching ignores case so that nicknames complete from lower case input
+	suffixes := [][]rune{}
+	for _, candidate := range candidates {
+		runes := []rune(candidate)
+		if len(runes) < len(current) || !strings.EqualFold(string(runes[:len(current)]), string(current)) {
+			continue
+		}
+		suffix := quoteCompletion(runes[len(current):], quote)
+		suffixes = append(suffixes, append(suffix, ' '))
+	}
+	return suffixes, len(current)
+}
+
+// completionWords splits the line typed so far with tokenize, the last word
+// being the one under the cursor, empty when a new word is starting. It also
+// returns the quote left open around that word, if any.
+func completionWords(typed string) ([]string, rune, bool) {
+	// A marker added at the cursor always ends up in the last word, even if
+	// that word is still empty or an open quote has to be closed around it
+	for _, quote := range []rune{0, '\'', '"'} {
+		closing := ""
+		if quote != 0 {
+			closing = string(quote)
+		}
+		words, err := tokenize(typed + "x" + closing)
+		if err != nil {
+			continue
+		}
+		words[len(words)-1] = strings.TrimSuffix(words[len(words)-1], "x")
+		return words, quote, true
+	}
+	return nil, 0, false
+}
+
+// quoteCompletion escapes the rest of a completed word so it tokenizes back
+// to the same word, and closes the quote it was typed in.
+func quoteCompletion(rest []rune, quote rune) []rune {
+	switch quote {
+	case '\'':
+		return append(rest, quote)
+	case '"':
+		escaped := []rune{}
+		for _, r := range rest {
+			if r == '"' || r == '\\' {
+				escaped = append(escaped, '\\')
+			}
+			escaped = append(escaped, r)
+		}
+		return append(escaped, quote)
+	}
+
+	escaped := []rune{}
+	for _, r := range rest {
+		if strings.ContainsRune(" \t'\"\\", r) {
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, r)
+	}
+	return escaped
+}
+
+func (c *replCompleter) commandNames() []string {
+	names := []string{}
+	for name := range getCommands() {
+		names = append(names, name)
+	}
+	names = append(names, c.cfg.aliases.Names()...)
+	sort.Strings(names)
+	return names
+}
+
+func (c *replCompleter) argumentNames(commandName string) []string {
+	// Built-in aliases complete like the command they stand for
+	if cmd, ok := lookupCommand(commandName); ok {
+		commandName = cmd.name
+	}
+
+	switch commandName {
+	case "catch", "species", "evolutions", "moves", "compare":
+		return c.nameIndex("pokemon")
+	case "explore":
+		return c.nameIndex("location-area")
+	case "types":
+		return c.nameIndex("type")
+	case "move":
+		return c.nameIndex("move")
+	case "ability":
+		return c.nameIndex("ability")
+	case "items":
+		return c.nameIndex("item-category")
+	case "item":
+		return c.nameIndex("item")
+	case "berry":
+		return c.nameIndex("berry")
+	case "inspect", "evolve", "train", "give":
+		names := []string{}
+		for name, caught := range c.cfg.caughtPokemon {
+			names = append(names, name)
+			if caught.Nickname != "" {
+				names = append(names, caught.Nickname)
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+	return nil
+}
+
+func (c *replCompleter) nameIndex(resource string) []string {
+	c.mu.Lock()
+	names, ok := c.nameIndexes[resource]
+	c.mu.Unlock()
+	if ok {
+		return names
+	}
+
+	// Messages would garble the line being edited, so fetch silently
+	names, err := getNameIndexLogged(c.cfg, resource, io.Discard)
+	if err != nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	c.nameIndexes[resource] = names
+	c.mu.Unlock()
+	return names
+}
+
+// prefetch loads the name indexes completion uses, so the first Tab does not
+// wait on the network. It is meant to run in the background.
+func (c *replCompleter) prefetch() {
+	for _, resource := range []string{"pokemon", "location-area"} {
+		c.nameIndex(resource)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/see-why/Pokedex/internal/evolution"
+)
+
+type evolutionsResult struct {
+	Pokemon string        `json:"pokemon"`
+	Chain   evolutionNode `json:"chain"`
+}
+
+// evolutionNode is one species of an evolution tree. How describes the ways
+// it evolves from its parent and is empty for the root.
+type evolutionNode struct {
+	Species   string          `json:"species"`
+	How       string          `json:"how,omitempty"`
+	EvolvesTo []evolutionNode `json:"evolves_to"`
+}
+
+func newEvolutionNode(link evolution.Link) evolutionNode {
+	node := evolutionNode{
+		Species:   link.Species.Name,
+		How:       evolution.Describe(link.Details),
+		EvolvesTo: []evolutionNode{},
+	}
+	for _, next := range link.EvolvesTo {
+		node.EvolvesTo = append(node.EvolvesTo, newEvolutionNode(next))
+	}
+	return node
+}
+
+func (r evolutionsResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, r.Chain.Species)
+	r.Chain.writeBranches(w, "")
+}
+
+// writeBranches draws the species a node evolves into as a tree, e.g.
+//
+//	eevee
+//	├── vaporeon (use water-stone)
+//	└── espeon (level up, happiness 160+, during the day)
+func (n evolutionNode) writeBranches(w io.Writer, indent string) {
+	for i, next := range n.EvolvesTo {
+		branch, childIndent := "├── ", "│   "
+		if i == len(n.EvolvesTo)-1 {
+			branch, childIndent = "└── ", "    "
+		}
+		if next.How != "" {
+			fmt.Fprintf(w, "%s%s%s (%s)\n", indent, branch, next.Species, next.How)
+		} else {
+			fmt.Fprintf(w, "%s%s%s\n", indent, branch, next.Species)
+		}
+		next.writeBranches(w, indent+childIndent)
+	}
+}
+
+func (r evolutionsResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	var walk func(evolutionNode)
+	walk = func(node evolutionNode) {
+		for _, next := range node.EvolvesTo {
+			rows = append(rows, []string{node.Species, next.Species, next.How})
+			walk(next)
+		}
+	}
+	walk(r.Chain)
+	return []string{"from", "to", "how"}, rows
+}
+
+func commandEvolutions(cfg *config, _ commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide a Pokemon name")
+	}
+
+	species, chain, err := getEvolutionChainOf(cfg, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return evolutionsResult{Pokemon: species.Name, Chain: newEvolutionNode(chain.Root)}, nil
+}
+
+// getEvolutionChainOf returns the species called name, or the species of the
+// Pokemon called name, together with its evolution chain.
+func getEvolutionChainOf(cfg *config, name string) (pokemonSpeciesResp, evolution.Chain, error) {
+	species, err := getSpeciesOf(cfg, name)
+	if err != nil {
+		return pokemonSpeciesResp{}, evolution.Chain{}, err
+	}
+	if species.EvolutionChain.URL == "" {
+		return species, evolution.Chain{}, fmt.Errorf("%s has no evolution chain", species.Name)
+	}
+
+	chain, err := fetchResource[evolution.Chain](cfg, species.EvolutionChain.URL)
+	return species, chain, err
+}
+
+// evolvesInto answers what the species or Pokemon called name evolves into,
+// and how. It is empty when the species is fully evolved.
+func evolvesInto(cfg *config, name string) ([]evolution.Edge, error) {
+	species, chain, err := getEvolutionChainOf(cfg, name)
+	if err != nil {
+		return nil, err
+	}
+	return chain.EvolvesInto(species.Name), nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/see-why/Pokedex/internal/evolution"
+)
+
+type evolveResult struct {
+	Pokemon  string `json:"pokemon"`
+	Nickname string `json:"nickname,omitempty"`
+	Into     string `json:"into"`
+	Level    int    `json:"level"`
+}
+
+func (r evolveResult) writeText(w io.Writer) {
+	name := r.Pokemon
+	if r.Nickname != "" {
+		name = r.Nickname
+	}
+	fmt.Fprintf(w, "What? %s is evolving!\n", name)
+	fmt.Fprintf(w, "Congratulations! Your %s evolved into %s!\n", name, r.Into)
+}
+
+func commandEvolve(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide the name or nickname of a caught Pokemon")
+	}
+
+	owned, exists := findCaughtPokemon(cfg, args[0])
+	if !exists {
+		return nil, fmt.Errorf("you have not caught %q", args[0])
+	}
+
+	speciesName := owned.Species.Name
+	if speciesName == "" {
+		speciesName = owned.Name
+	}
+	species, chain, err := getEvolutionChainOf(cfg, speciesName)
+	if err != nil {
+		return nil, err
+	}
+	edges := chain.EvolvesInto(species.Name)
+	if len(edges) == 0 {
+		return messageResult{Message: fmt.Sprintf("%s does not evolve any further", owned.displayName())}, nil
+	}
+
+	if flags.has("into") {
+		into := apiName(flags.get("into"))
+		edges = filterEdges(edges, func(edge evolution.Edge) bool { return edge.To == into })
+		if len(edges) == 0 {
+			return nil, fmt.Errorf("%s cannot evolve into %s", owned.displayName(), into)
+		}
+	}
+
+	state := evolution.State{
+		Level:      owned.Level,
+		Friendship: owned.Friendship,
+		HeldItem:   owned.HeldItem,
+		UsedItem:   apiName(flags.get("item")),
+		Hour:       cfg.currentTime().Hour(),
+	}
+	ready := filterEdges(edges, func(edge evolution.Edge) bool {
+		ok, _ := edge.Check(state)
+		return ok
+	})
+	if len(ready) == 0 {
+		return messageResult{Message: notReadyMessage(owned, edges, state)}, nil
+	}
+
+	edge := ready[0]
+	if len(ready) > 1 {
+		edge, err = chooseEvolution(cfg, owned, ready)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	evolved, err := getDefaultPokemon(cfg, edge.To)
+	if err != nil {
+		return nil, err
+	}
+	// Caught Pokemon are kept one per species, so evolving into one already
+	// caught would replace it
+	if existing, ok := cfg.caughtPokemon[evolved.Name]; ok {
+		return nil, fmt.Errorf("%s cannot evolve into %s, you already have %s", owned.displayName(), evolved.Name, existing.displayName())
+	}
+
+	// The item an evolution needs held is used up, as in the games
+	if way, _ := edge.Way(state); way.HeldItem != nil {
+		owned.HeldItem = ""
+	}
+	previous := owned.Name
+	owned.Pokemon = evolved
+	owned.History = append(owned.History, fmt.Sprintf("evolved from %s into %s at level %d", previous, evolved.Name, owned.Level))
+	delete(cfg.caughtPokemon, previous)
+	cfg.caughtPokemon[evolved.Name] = owned
+
+	return evolveResult{Pokemon: previous, Nickname: owned.Nickname, Into: evolved.Name, Level: owned.Level}, nil
+}
+
+func filterEdges(edges []evolution.Edge, keep func(evolution.Edge) bool) []evolution.Edge {
+	kept := []evolution.Edge{}
+	for _, edge := range edges {
+		if keep(edge) {
+			kept = append(kept, edge)
+		}
+	}
+	return kept
+}
+
+// notReadyMessage explains what each evolution of owned still needs.
+func notReadyMessage(owned ownedPokemon, edges []evolution.Edge, state evolution.State) string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "%s is not ready to evolve", owned.displayName())
+	for _, edge := range edges {
+		_, missing := edge.Check(state)
+		fmt.Fprintf(&message, "\n  %s needs %s", edge.To, strings.Join(missing, ", "))
+	}
+	return message.String()
+}
+
+// chooseEvolution asks which of several evolutions owned should take. Without
+// anyone to ask, the choice has to be made with --into.
+func chooseEvolution(cfg *config, owned ownedPokemon, edges []evolution.Edge) (evolution.Edge, error) {
+	names := make([]string, len(edges))
+	for i, edge := range edges {
+		names[i] = edge.To
+	}
+
+	if cfg.choose == nil {
+		return evolution.Edge{}, fmt.Errorf("%s can evolve into %s, choose one with --into", owned.displayName(), strings.Join(names, " or "))
+	}
+	choice, err := cfg.choose(fmt.Sprintf("What should %s evolve into?", owned.displayName()), names)
+	if err != nil {
+		return evolution.Edge{}, err
+	}
+	return edges[choice], nil
+}
+
+// getDefaultPokemon returns the default form of a species. Most share the
+// species name; the others are found through the species' varieties.
+func getDefaultPokemon(cfg *config, speciesName string) (Pokemon, error) {
+	pokemon, err := getPokemon(cfg, speciesName)
+	if !errors.Is(err, errNotFound) {
+		return pokemon, err
+	}
+
+	species, err := getPokemonSpecies(cfg, speciesName)
+	if err != nil {
+		return Pokemon{}, err
+	}
+	for _, variety := range species.Varieties {
+		if variety.IsDefault {
+			return getPokemon(cfg, variety.Pokemon.Name)
+		}
+	}
+	return Pokemon{}, fmt.Errorf("%s has no default form", speciesName)
+}
+
+type trainResult struct {
+	Pokemon    string `json:"pokemon"`
+	Level      int    `json:"level"`
+	Friendship int    `json:"friendship"`
+}
+
+func (r trainResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s grew to level %d! Friendship is now %d.\n", r.Pokemon, r.Level, r.Friendship)
+}
+
+func commandTrain(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide the name or nickname of a caught Pokemon")
+	}
+
+	levels := 1
+	if flags.has("levels") {
+		var err error
+		levels, err = flags.int("levels")
+		if err != nil {
+			return nil, err
+		}
+		if levels < 1 {
+			return nil, fmt.Errorf("--levels must be at least 1")
+		}
+	}
+
+	owned, exists := findCaughtPokemon(cfg, args[0])
+	if !exists {
+		return nil, fmt.Errorf("you have not caught %q", args[0])
+	}
+	if owned.Level >= maxLevel {
+		return messageResult{Message: fmt.Sprintf("%s is already level %d", owned.displayName(), maxLevel)}, nil
+	}
+
+	for i := 0; i < levels && owned.Level < maxLevel; i++ {
+		owned.Level++
+		owned.Friendship = min(maxFriendship, owned.Friendship+friendshipPerLevel(owned.Friendship))
+	}
+	cfg.caughtPokemon[owned.Name] = owned
+
+	return trainResult{Pokemon: owned.displayName(), Level: owned.Level, Friendship: owned.Friendship}, nil
+}
+
+// friendshipPerLevel is how much friendship a level up brings, which, as in
+// the games, shrinks as the Pokemon grows fond of its trainer.
+func friendshipPerLevel(friendship int) int {
+	switch {
+	case friendship < 100:
+		return 5
+	case friendship < 200:
+		return 3
+	default:
+		return 2
+	}
+}
+
+func commandGive(cfg *config, _ commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide the name or nickname of a caught Pokemon")
+	}
+
+	owned, exists := findCaughtPokemon(cfg, args[0])
+	if !exists {
+		return nil, fmt.Errorf("you have not caught %q", args[0])
+	}
+
+	var message string
+	switch {
+	case len(args) > 1:
+		owned.HeldItem = apiName(args[1])
+		message = fmt.Sprintf("%s is now holding %s", owned.displayName(), owned.HeldItem)
+	case owned.HeldItem != "":
+		message = fmt.Sprintf("Took %s from %s", owned.HeldItem, owned.displayName())
+		owned.HeldItem = ""
+	default:
+		message = fmt.Sprintf("%s is not holding anything", owned.displayName())
+	}
+	cfg.caughtPokemon[owned.Name] = owned
+
+	return messageResult{Message: message}, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/see-why/Pokedex/internal/fuzzy"
+)
+
+type commandCategory string
+
+const (
+	categoryNavigation commandCategory = "navigation"
+	categoryCollection commandCategory = "collection"
+	categoryBattle     commandCategory = "battle"
+	categorySystem     commandCategory = "system"
+)
+
+// commandCategories lists the categories in the order help shows them.
+var commandCategories = []commandCategory{
+	categoryNavigation,
+	categoryCollection,
+	categoryBattle,
+	categorySystem,
+}
+
+type helpResult struct {
+	Categories []helpCategory `json:"categories"`
+}
+
+type helpCategory struct {
+	Name     string      `json:"name"`
+	Commands []helpEntry `json:"commands"`
+}
+
+type helpEntry struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (r helpResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "Welcome to the Pokedex!")
+	fmt.Fprintln(w, "Usage:")
+	for _, category := range r.Categories {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%s:\n", strings.ToUpper(category.Name[:1])+category.Name[1:])
+		for _, cmd := range category.Commands {
+			fmt.Fprintf(w, "  %s: %s\n", cmd.Name, cmd.Description)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Use "help <command>" for usage, arguments and examples.`)
+}
+
+func (r helpResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, category := range r.Categories {
+		for _, cmd := range category.Commands {
+			rows = append(rows, []string{cmd.Name, category.Name, cmd.Description})
+		}
+	}
+	return []string{"command", "category", "description"}, rows
+}
+
+type commandHelpResult struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Category    string     `json:"category"`
+	Usage       string     `json:"usage"`
+	Arguments   []helpItem `json:"arguments"`
+	Flags       []helpItem `json:"flags"`
+	Examples    []string   `json:"examples"`
+	Aliases     []string   `json:"aliases"`
+}
+
+type helpItem struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (r commandHelpResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s - %s\n", r.Name, r.Description)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Usage: %s\n", r.Usage)
+
+	writeHelpItems(w, "Arguments", r.Arguments)
+	writeHelpItems(w, "Flags", r.Flags)
+
+	if len(r.Examples) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Examples:")
+		for _, example := range r.Examples {
+			fmt.Fprintf(w, "  %s\n", example)
+		}
+	}
+
+	if len(r.Aliases) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Aliases: %s\n", strings.Join(r.Aliases, ", "))
+	}
+}
+
+func writeHelpItems(w io.Writer, heading string, items []helpItem) {
+	if len(items) == 0 {
+		return
+	}
+
+	width := 0
+	for _, item := range items {
+		width = max(width, len(item.Name))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s:\n", heading)
+	for _, item := range items {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, item.Name, item.Description)
+	}
+}
+
+func commandHelp(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	commands := getCommands()
+
+	if len(args) > 0 {
+		cmd, exists := lookupCommand(args[0])
+		if !exists {
+			if expansion, ok := cfg.aliases.Get(args[0]); ok {
+				return messageResult{Message: fmt.Sprintf("%s is an alias for %s", args[0], quoteArg(expansion))}, nil
+			}
+			names := make([]string, 0, len(commands))
+			for name := range commands {
+				names = append(names, name)
+			}
+			names = append(names, cfg.aliases.Names()...)
+			return nil, withSuggestions(fmt.Sprintf("unknown command %q", args[0]), fuzzy.Closest(args[0], names, maxSuggestions))
+		}
+		return newCommandHelpResult(cmd), nil
+	}
+
+	byCategory := map[commandCategory][]helpEntry{}
+	for _, cmd := range commands {
+		byCategory[cmd.category] = append(byCategory[cmd.category], helpEntry{Name: cmd.name, Description: cmd.description})
+	}
+
+	result := helpResult{Categories: []helpCategory{}}
+	for _, category := range commandCategories {
+		entries := byCategory[category]
+		if len(entries) == 0 {
+			continue
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Name < entries[j].Name
+		})
+		result.Categories = append(result.Categories, helpCategory{Name: string(category), Commands: entries})
+	}
+
+	return result, nil
+}
+
+func newCommandHelpResult(cmd cliCommand) commandHelpResult {
+	result := commandHelpResult{
+		Name:        cmd.name,
+		Description: cmd.description,
+		Category:    string(cmd.category),
+		Usage:       commandUsage(cmd),
+		Arguments:   []helpItem{},
+		Flags:       []helpItem{},
+		Examples:    []string{},
+		Aliases:     []string{},
+	}
+
+	for _, arg := range cmd.args {
+		result.Arguments = append(result.Arguments, helpItem{Name: arg.name, Description: arg.description})
+	}
+	for _, flag := range cmd.flags {
+		result.Flags = append(result.Flags, helpItem{Name: flagUsage(flag), Description: flag.description})
+	}
+	result.Examples = append(result.Examples, cmd.examples...)
+	result.Aliases = append(result.Aliases, cmd.aliases...)
+
+	return result
+}
+
+// commandUsage builds the usage line for cmd from its flags and arguments,
+// e.g. "map [--page N] [--limit N]" or "explore [location-area]".
+func commandUsage(cmd cliCommand) string {
+	parts := []string{cmd.name}
+	for _, flag := range cmd.flags {
+		parts = append(parts, "["+flagUsage(flag)+"]")
+	}
+	for _, arg := range cmd.args {
+		name := arg.name
+		if arg.variadic {
+			name += "..."
+		}
+		if arg.optional {
+			parts = append(parts, "["+name+"]")
+		} else {
+			parts = append(parts, "<"+name+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func flagUsage(flag commandFlag) string {
+	if flag.value == "" {
+		return "--" + flag.name
+	}
+	return "--" + flag.name + " " + flag.value
+}
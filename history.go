@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chzyer/readline"
+)
+
+// defaultHistoryLimit is how many commands are kept when history_size is not
+// set.
+const defaultHistoryLimit = 1000
+
+// replHistory is the list of previously entered commands, oldest first,
+// persisted to a file so it survives between sessions. Re-entering a command
+// moves it to the end instead of storing it twice.
+type replHistory struct {
+	path    string
+	limit   int
+	entries []string
+}
+
+// loadHistory reads the history stored at path. A missing file is not an
+// error, and an empty path keeps the history in memory only.
+func loadHistory(path string, limit int) (*replHistory, error) {
+	h := &replHistory{
+		path:    path,
+		limit:   limit,
+		entries: []string{},
+	}
+	if path == "" {
+		return h, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.push(scanner.Text())
+	}
+
+	return h, scanner.Err()
+}
+
+// Add records line as the most recent command and saves the history.
+func (h *replHistory) Add(line string) error {
+	h.push(line)
+	return h.save()
+}
+
+// Entries returns the recorded commands, oldest first.
+func (h *replHistory) Entries() []string {
+	return h.entries
+}
+
+// Expand resolves a history reference: "!!" is the most recent command and
+// "!N" is the command numbered N in the history listing.
+func (h *replHistory) Expand(line string) (string, error) {
+	ref := strings.TrimPrefix(strings.TrimSpace(line), "!")
+	if len(h.entries) == 0 {
+		return "", fmt.Errorf("history is empty")
+	}
+	if ref == "!" {
+		return h.entries[len(h.entries)-1], nil
+	}
+
+	n, err := strconv.Atoi(ref)
+	if err != nil || n < 1 || n > len(h.entries) {
+		return "", fmt.Errorf("no history entry %q", "!"+ref)
+	}
+	return h.entries[n-1], nil
+}
+
+func (h *replHistory) push(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	// Drop the earlier copy so each command appears once
+	if i := slices.Index(h.entries, line); i >= 0 {
+		h.entries = slices.Delete(h.entries, i, i+1)
+	}
+	h.entries = append(h.entries, line)
+
+	if h.limit > 0 && len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+	}
+}
+
+func (h *replHistory) save() error {
+	if h.path == "" {
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(h.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	content := strings.Join(h.entries, "\n") + "\n"
+	return os.WriteFile(h.path, []byte(content), 0o600)
+}
+
+// syncReadlineHistory replaces the entries readline offers on ↑/↓ with the
+// de-duplicated history.
+func syncReadlineHistory(rl *readline.Instance, h *replHistory) {
+	rl.ResetHistory()
+	for _, entry := range h.Entries() {
+		rl.SaveHistory(entry)
+	}
+}
+
+// dataDir returns the directory for files the Pokedex keeps between
+// sessions, following the XDG base directory convention.
+func dataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "pokedex"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "pokedex"), nil
+}
+
+type historyResult struct {
+	Entries []historyEntry `json:"entries"`
+}
+
+type historyEntry struct {
+	Number  int    `json:"number"`
+	Command string `json:"command"`
+}
+
+func (r historyResult) writeText(w io.Writer) {
+	if len(r.Entries) == 0 {
+		fmt.Fprintln(w, "No commands in history")
+		return
+	}
+
+	for _, entry := range r.Entries {
+		fmt.Fprintf(w, "%5d  %s\n", entry.Number, entry.Command)
+	}
+}
+
+func (r historyResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, entry := range r.Entries {
+		rows = append(rows, []string{strconv.Itoa(entry.Number), entry.Command})
+	}
+	return []string{"#", "command"}, rows
+}
+
+func commandHistory(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	result := historyResult{Entries: []historyEntry{}}
+	if cfg.history != nil {
+		for i, entry := range cfg.history.Entries() {
+			result.Entries = append(result.Entries, historyEntry{Number: i + 1, Command: entry})
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package ansi
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const reset = "\x1b[0m"
+
+// styles maps style names to their SGR parameters.
+var styles = map[string]string{
+	"bold":      "1",
+	"dim":       "2",
+	"italic":    "3",
+	"underline": "4",
+	"black":     "30",
+	"red":       "31",
+	"green":     "32",
+	"yellow":    "33",
+	"blue":      "34",
+	"magenta":   "35",
+	"cyan":      "36",
+	"white":     "37",
+	"gray":      "90",
+}
+
+// Paint wraps text in the escape sequences for the given styles, which are
+// style names such as "bold" or "cyan", "#rrggbb" for a 24-bit foreground
+// color, or "color-N" for color N of the xterm 256. With no styles, text is
+// returned unchanged.
+func Paint(text string, names ...string) (string, error) {
+	if len(names) == 0 {
+		return text, nil
+	}
+
+	params := make([]string, 0, len(names))
+	for _, name := range names {
+		param, err := sgr(name)
+		if err != nil {
+			return "", err
+		}
+		params = append(params, param)
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m" + text + reset, nil
+}
+
+// Valid reports whether name is a style Paint accepts.
+func Valid(name string) bool {
+	_, err := sgr(name)
+	return err == nil
+}
+
+func sgr(name string) (string, error) {
+	name = strings.ToLower(name)
+	if param, ok := styles[name]; ok {
+		return param, nil
+	}
+
+	if r, g, b, ok := parseHex(name); ok {
+		return fmt.Sprintf("38;2;%d;%d;%d", r, g, b), nil
+	}
+	if n, ok := strings.CutPrefix(name, "color-"); ok {
+		if index, err := strconv.Atoi(n); err == nil && index >= 0 && index <= 255 {
+			return fmt.Sprintf("38;5;%d", index), nil
+		}
+	}
+	return "", fmt.Errorf("unknown style %q", name)
+}
+
+func parseHex(name string) (r, g, b int, ok bool) {
+	if !strings.HasPrefix(name, "#") || len(name) != 7 {
+		return 0, 0, 0, false
+	}
+	rgb, err := strconv.ParseUint(name[1:], 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int(rgb >> 16), int(rgb >> 8 & 0xff), int(rgb & 0xff), true
+}
+
+// Reduce turns a "#rrggbb" style into the nearest "color-N" style, for
+// terminals without 24-bit color. Other styles are returned unchanged.
+func Reduce(name string) string {
+	r, g, b, ok := parseHex(strings.ToLower(name))
+	if !ok {
+		return name
+	}
+	return fmt.Sprintf("color-%d", Xterm256(r, g, b))
+}
+
+// cubeLevels are the channel values of the xterm 6x6x6 color cube.
+var cubeLevels = []int{0, 95, 135, 175, 215, 255}
+
+// Xterm256 returns the xterm 256 color index nearest to r, g, b, choosing
+// between the color cube and the grayscale ramp.
+func Xterm256(r, g, b int) int {
+	ri, gi, bi := nearestLevel(r), nearestLevel(g), nearestLevel(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDistance := distance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	// The grayscale ramp runs from 8 to 238 in steps of 10
+	average := (r + g + b) / 3
+	grayIndex := min(max((average-8+5)/10, 0), 23)
+	grayLevel := 8 + 10*grayIndex
+	if distance(r, g, b, grayLevel, grayLevel, grayLevel) < cubeDistance {
+		return 232 + grayIndex
+	}
+	return cube
+}
+
+func nearestLevel(v int) int {
+	best := 0
+	for i, level := range cubeLevels {
+		if abs(v-level) < abs(v-cubeLevels[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+func distance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+var escapes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// Strip removes the escape sequences Paint adds, leaving the plain text.
+func Strip(text string) string {
+	return escapes.ReplaceAllString(text, "")
+}
@@ -0,0 +1,84 @@
+package ansi
+
+import "testing"
+
+func TestPaint(t *testing.T) {
+	cases := []struct {
+		text     string
+		styles   []string
+		expected string
+	}{
+		{text: "pikachu", styles: nil, expected: "pikachu"},
+		{text: "pikachu", styles: []string{"yellow"}, expected: "\x1b[33mpikachu\x1b[0m"},
+		{text: "caught", styles: []string{"Bold", "green"}, expected: "\x1b[1;32mcaught\x1b[0m"},
+		{text: "fire", styles: []string{"#EE8130"}, expected: "\x1b[38;2;238;129;48mfire\x1b[0m"},
+		{text: "fire", styles: []string{"color-209"}, expected: "\x1b[38;5;209mfire\x1b[0m"},
+	}
+
+	for _, c := range cases {
+		actual, err := Paint(c.text, c.styles...)
+		if err != nil {
+			t.Errorf("Paint(%q, %q) returned unexpected error: %v", c.text, c.styles, err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("Paint(%q, %q) = %q, expected %q", c.text, c.styles, actual, c.expected)
+		}
+	}
+}
+
+func TestPaint_UnknownStyle(t *testing.T) {
+	for _, style := range []string{"purple", "#12345", "#gggggg", "color-256"} {
+		if _, err := Paint("text", style); err == nil {
+			t.Errorf("Paint with style %q expected an error", style)
+		}
+		if Valid(style) {
+			t.Errorf("Valid(%q) = true, expected false", style)
+		}
+	}
+}
+
+func TestStrip(t *testing.T) {
+	painted, _ := Paint("pallet-town-area", "bold", "#ff0000")
+	if actual := Strip("[" + painted + "] > "); actual != "[pallet-town-area] > " {
+		t.Errorf("Strip returned %q", actual)
+	}
+}
+
+func TestXterm256(t *testing.T) {
+	cases := []struct {
+		r, g, b  int
+		expected int
+	}{
+		{r: 0, g: 0, b: 0, expected: 16},
+		{r: 255, g: 255, b: 255, expected: 231},
+		{r: 255, g: 0, b: 0, expected: 196},
+		{r: 128, g: 128, b: 128, expected: 244},
+		{r: 238, g: 129, b: 48, expected: 209},
+	}
+
+	for _, c := range cases {
+		if actual := Xterm256(c.r, c.g, c.b); actual != c.expected {
+			t.Errorf("Xterm256(%d, %d, %d) = %d, expected %d", c.r, c.g, c.b, actual, c.expected)
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	cases := map[string]string{
+		"#EE8130": "color-209",
+		"#000000": "color-16",
+		"bold":    "bold",
+		"#12345":  "#12345",
+	}
+	for name, expected := range cases {
+		if actual := Reduce(name); actual != expected {
+			t.Errorf("Reduce(%q) = %q, expected %q", name, actual, expected)
+		}
+	}
+
+	painted, err := Paint("fire", Reduce("#EE8130"))
+	if err != nil || painted != "\x1b[38;5;209mfire\x1b[0m" {
+		t.Errorf("Paint with a reduced color = %q, %v", painted, err)
+	}
+}
@@ -0,0 +1,318 @@
+package evolution
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Chain is a PokeAPI evolution chain: a tree of species rooted at the
+// species that does not evolve from anything.
+type Chain struct {
+	ID   int  `json:"id"`
+	Root Link `json:"chain"`
+}
+
+// Link is one species in a chain, with the ways it is reached from its
+// parent and the species it evolves into.
+type Link struct {
+	Species   Resource `json:"species"`
+	IsBaby    bool     `json:"is_baby"`
+	Details   []Detail `json:"evolution_details"`
+	EvolvesTo []Link   `json:"evolves_to"`
+}
+
+// Resource is a named PokeAPI resource.
+type Resource struct {
+	Name string `json:"name"`
+}
+
+// Detail is one way of evolving: what triggers it and the conditions that
+// must hold. A species can have several, typically one per game.
+type Detail struct {
+	Trigger               Resource  `json:"trigger"`
+	Item                  *Resource `json:"item"`
+	HeldItem              *Resource `json:"held_item"`
+	KnownMove             *Resource `json:"known_move"`
+	KnownMoveType         *Resource `json:"known_move_type"`
+	Location              *Resource `json:"location"`
+	PartySpecies          *Resource `json:"party_species"`
+	PartyType             *Resource `json:"party_type"`
+	TradeSpecies          *Resource `json:"trade_species"`
+	MinLevel              *int      `json:"min_level"`
+	MinHappiness          *int      `json:"min_happiness"`
+	MinAffection          *int      `json:"min_affection"`
+	MinBeauty             *int      `json:"min_beauty"`
+	Gender                *int      `json:"gender"`
+	RelativePhysicalStats *int      `json:"relative_physical_stats"`
+	TimeOfDay             string    `json:"time_of_day"`
+	NeedsOverworldRain    bool      `json:"needs_overworld_rain"`
+	TurnUpsideDown        bool      `json:"turn_upside_down"`
+}
+
+// Edge is an evolution from one species into another.
+type Edge struct {
+	From    string
+	To      string
+	Details []Detail
+}
+
+// Find returns the link of the named species.
+func (c Chain) Find(species string) (Link, bool) {
+	return c.Root.find(species)
+}
+
+func (l Link) find(species string) (Link, bool) {
+	if l.Species.Name == species {
+		return l, true
+	}
+	for _, next := range l.EvolvesTo {
+		if found, ok := next.find(species); ok {
+			return found, true
+		}
+	}
+	return Link{}, false
+}
+
+// EvolvesInto answers what the named species evolves into, and how. It is
+// empty for fully evolved species and species not in the chain.
+func (c Chain) EvolvesInto(species string) []Edge {
+	link, ok := c.Find(species)
+	if !ok {
+		return nil
+	}
+
+	edges := make([]Edge, 0, len(link.EvolvesTo))
+	for _, next := range link.EvolvesTo {
+		edges = append(edges, Edge{From: species, To: next.Species.Name, Details: next.Details})
+	}
+	return edges
+}
+
+// EvolvesFrom returns the evolution that produces the named species, if it
+// evolves from anything.
+func (c Chain) EvolvesFrom(species string) (Edge, bool) {
+	return c.Root.evolvesFrom(species)
+}
+
+func (l Link) evolvesFrom(species string) (Edge, bool) {
+	for _, next := range l.EvolvesTo {
+		if next.Species.Name == species {
+			return Edge{From: l.Species.Name, To: species, Details: next.Details}, true
+		}
+		if edge, ok := next.evolvesFrom(species); ok {
+			return edge, true
+		}
+	}
+	return Edge{}, false
+}
+
+// String describes how the evolution happens, e.g. "level 16",
+// "use water-stone" or "level up, happiness 160+, during the day".
+func (d Detail) String() string {
+	parts := []string{}
+	for _, req := range d.requirements() {
+		parts = append(parts, req.text)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Conditions lists the requirements of the evolution besides its trigger,
+// in words. The level of a level-up and the item of a use-item evolution
+// are part of the trigger and not repeated.
+func (d Detail) Conditions() []string {
+	conditions := []string{}
+	for _, req := range d.requirements() {
+		if !req.trigger {
+			conditions = append(conditions, req.text)
+		}
+	}
+	return conditions
+}
+
+// State is what is known about a Pokemon when it tries to evolve.
+type State struct {
+	Level      int
+	Friendship int
+	// HeldItem is the item the Pokemon holds and UsedItem the item used on
+	// it to make it evolve, if any.
+	HeldItem string
+	UsedItem string
+	// Hour is the hour of the day, from 0 to 23.
+	Hour int
+}
+
+// Unmet lists the requirements of the evolution that state does not meet,
+// in words. Requirements that state cannot express, such as a trade or a
+// known move, are never met.
+func (d Detail) Unmet(state State) []string {
+	unmet := []string{}
+	for _, req := range d.requirements() {
+		if req.met == nil || !req.met(state) {
+			unmet = append(unmet, req.text)
+		}
+	}
+	return unmet
+}
+
+// Check reports whether state meets one of the ways of the evolution. When
+// it does not, missing lists what the closest way still needs.
+func (e Edge) Check(state State) (ok bool, missing []string) {
+	for i, detail := range e.Details {
+		unmet := detail.Unmet(state)
+		if len(unmet) == 0 {
+			return true, nil
+		}
+		if i == 0 || len(unmet) < len(missing) {
+			missing = unmet
+		}
+	}
+	return false, missing
+}
+
+// Way returns the first way of the evolution that state meets.
+func (e Edge) Way(state State) (Detail, bool) {
+	for _, detail := range e.Details {
+		if len(detail.Unmet(state)) == 0 {
+			return detail, true
+		}
+	}
+	return Detail{}, false
+}
+
+// requirement is one thing an evolution needs, with a check against a
+// State when one is possible.
+type requirement struct {
+	text string
+	// trigger marks the requirement that describes the trigger itself.
+	trigger bool
+	met     func(State) bool
+}
+
+func (d Detail) requirements() []requirement {
+	reqs := []requirement{}
+	add := func(text string, met func(State) bool) {
+		reqs = append(reqs, requirement{text: text, met: met})
+	}
+
+	switch {
+	case d.Trigger.Name == "level-up" && d.MinLevel != nil:
+		level := *d.MinLevel
+		reqs = append(reqs, requirement{text: fmt.Sprintf("level %d", level), trigger: true, met: func(s State) bool {
+			return s.Level >= level
+		}})
+	case d.Trigger.Name == "level-up":
+		reqs = append(reqs, requirement{text: "level up", trigger: true, met: func(State) bool { return true }})
+	case d.Trigger.Name == "use-item" && d.Item != nil:
+		item := d.Item.Name
+		reqs = append(reqs, requirement{text: "use " + item, trigger: true, met: func(s State) bool {
+			return s.UsedItem == item
+		}})
+	case d.Trigger.Name != "":
+		reqs = append(reqs, requirement{text: strings.ReplaceAll(d.Trigger.Name, "-", " "), trigger: true})
+	}
+
+	if d.MinLevel != nil && d.Trigger.Name != "level-up" {
+		level := *d.MinLevel
+		add(fmt.Sprintf("level %d", level), func(s State) bool { return s.Level >= level })
+	}
+	if d.Item != nil && d.Trigger.Name != "use-item" {
+		item := d.Item.Name
+		add("item "+item, func(s State) bool { return s.UsedItem == item })
+	}
+	if d.HeldItem != nil {
+		item := d.HeldItem.Name
+		add("holding "+item, func(s State) bool { return s.HeldItem == item })
+	}
+	if d.KnownMove != nil {
+		add("knows "+d.KnownMove.Name, nil)
+	}
+	if d.KnownMoveType != nil {
+		add("knows a "+d.KnownMoveType.Name+" move", nil)
+	}
+	if d.MinHappiness != nil {
+		happiness := *d.MinHappiness
+		add(fmt.Sprintf("happiness %d+", happiness), func(s State) bool { return s.Friendship >= happiness })
+	}
+	if d.MinAffection != nil {
+		add(fmt.Sprintf("affection %d+", *d.MinAffection), nil)
+	}
+	if d.MinBeauty != nil {
+		add(fmt.Sprintf("beauty %d+", *d.MinBeauty), nil)
+	}
+	switch d.TimeOfDay {
+	case "":
+	case "day":
+		add("during the day", func(s State) bool { return isDay(s.Hour) })
+	case "night":
+		add("at night", func(s State) bool { return !isDay(s.Hour) })
+	case "dusk":
+		add("at dusk", func(s State) bool { return s.Hour == duskHour })
+	default:
+		add("at "+d.TimeOfDay, nil)
+	}
+	if d.Gender != nil {
+		switch *d.Gender {
+		case 1:
+			add("female", nil)
+		case 2:
+			add("male", nil)
+		}
+	}
+	if d.Location != nil {
+		add("at "+d.Location.Name, nil)
+	}
+	if d.PartySpecies != nil {
+		add("with "+d.PartySpecies.Name+" in the party", nil)
+	}
+	if d.PartyType != nil {
+		add("with a "+d.PartyType.Name+" type in the party", nil)
+	}
+	if d.RelativePhysicalStats != nil {
+		switch *d.RelativePhysicalStats {
+		case 1:
+			add("attack higher than defense", nil)
+		case -1:
+			add("attack lower than defense", nil)
+		case 0:
+			add("attack equal to defense", nil)
+		}
+	}
+	if d.TradeSpecies != nil {
+		add("traded for "+d.TradeSpecies.Name, nil)
+	}
+	if d.NeedsOverworldRain {
+		add("while raining", nil)
+	}
+	if d.TurnUpsideDown {
+		add("with the console upside down", nil)
+	}
+	return reqs
+}
+
+// Day lasts from dayStartHour until nightStartHour; its last hour is dusk.
+const (
+	dayStartHour   = 6
+	nightStartHour = 18
+	duskHour       = nightStartHour - 1
+)
+
+func isDay(hour int) bool {
+	return hour >= dayStartHour && hour < nightStartHour
+}
+
+// Describe joins the ways an evolution can happen, e.g.
+// "level up, happiness 160+ or level up, at eterna-forest". Repeated ways,
+// which PokeAPI lists once per game, are shown once.
+func Describe(details []Detail) string {
+	ways := []string{}
+	seen := map[string]bool{}
+	for _, detail := range details {
+		way := detail.String()
+		if way == "" || seen[way] {
+			continue
+		}
+		seen[way] = true
+		ways = append(ways, way)
+	}
+	return strings.Join(ways, " or ")
+}
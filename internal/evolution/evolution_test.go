@@ -0,0 +1,238 @@
+package evolution
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadChain(t *testing.T, name string) Chain {
+	t.Helper()
+
+	dat, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chain Chain
+	if err := json.Unmarshal(dat, &chain); err != nil {
+		t.Fatalf("decoding %s: %v", name, err)
+	}
+	return chain
+}
+
+func TestEvolvesInto_Linear(t *testing.T) {
+	chain := loadChain(t, "bulbasaur.json")
+
+	cases := []struct {
+		species  string
+		expected string
+		how      string
+	}{
+		{species: "bulbasaur", expected: "ivysaur", how: "level 16"},
+		{species: "ivysaur", expected: "venusaur", how: "level 32"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.species, func(t *testing.T) {
+			edges := chain.EvolvesInto(c.species)
+			if len(edges) != 1 {
+				t.Fatalf("EvolvesInto(%s) returned %d edges, expected 1", c.species, len(edges))
+			}
+			if edges[0].From != c.species || edges[0].To != c.expected {
+				t.Errorf("EvolvesInto(%s) = %s -> %s, expected %s -> %s", c.species, edges[0].From, edges[0].To, c.species, c.expected)
+			}
+			if how := Describe(edges[0].Details); how != c.how {
+				t.Errorf("Describe = %q, expected %q", how, c.how)
+			}
+		})
+	}
+}
+
+func TestEvolvesInto_FullyEvolved(t *testing.T) {
+	chain := loadChain(t, "bulbasaur.json")
+
+	if edges := chain.EvolvesInto("venusaur"); len(edges) != 0 {
+		t.Errorf("EvolvesInto(venusaur) = %v, expected none", edges)
+	}
+	if edges := chain.EvolvesInto("pikachu"); len(edges) != 0 {
+		t.Errorf("EvolvesInto(pikachu) = %v, expected none", edges)
+	}
+}
+
+func TestEvolvesInto_Branches(t *testing.T) {
+	chain := loadChain(t, "eevee.json")
+
+	expected := map[string]string{
+		"vaporeon": "use water-stone",
+		"espeon":   "level up, happiness 160+, during the day",
+		"leafeon":  "level up, at eterna-forest or level up, at pinwheel-forest or use leaf-stone",
+		"sylveon":  "level up, knows a fairy move, affection 2+",
+	}
+
+	edges := chain.EvolvesInto("eevee")
+	if len(edges) != len(expected) {
+		t.Fatalf("EvolvesInto(eevee) returned %d edges, expected %d", len(edges), len(expected))
+	}
+	for _, edge := range edges {
+		how, ok := expected[edge.To]
+		if !ok {
+			t.Errorf("unexpected evolution into %s", edge.To)
+			continue
+		}
+		if actual := Describe(edge.Details); actual != how {
+			t.Errorf("Describe(%s) = %q, expected %q", edge.To, actual, how)
+		}
+	}
+}
+
+func TestEvolvesFrom(t *testing.T) {
+	chain := loadChain(t, "bulbasaur.json")
+
+	edge, ok := chain.EvolvesFrom("venusaur")
+	if !ok || edge.From != "ivysaur" {
+		t.Errorf("EvolvesFrom(venusaur) = %v, %v, expected ivysaur", edge, ok)
+	}
+	if _, ok := chain.EvolvesFrom("bulbasaur"); ok {
+		t.Error("EvolvesFrom(bulbasaur) found an evolution, expected none")
+	}
+}
+
+func TestDetailString_Conditions(t *testing.T) {
+	level := 30
+	female := 1
+	stats := 1
+
+	cases := []struct {
+		name     string
+		detail   Detail
+		expected string
+	}{
+		{
+			name:     "trade holding item",
+			detail:   Detail{Trigger: Resource{Name: "trade"}, HeldItem: &Resource{Name: "metal-coat"}},
+			expected: "trade, holding metal-coat",
+		},
+		{
+			name:     "level at night",
+			detail:   Detail{Trigger: Resource{Name: "level-up"}, MinLevel: &level, TimeOfDay: "night"},
+			expected: "level 30, at night",
+		},
+		{
+			name:     "gender and stats",
+			detail:   Detail{Trigger: Resource{Name: "level-up"}, MinLevel: &level, Gender: &female, RelativePhysicalStats: &stats},
+			expected: "level 30, female, attack higher than defense",
+		},
+		{
+			name:     "rain and upside down",
+			detail:   Detail{Trigger: Resource{Name: "level-up"}, NeedsOverworldRain: true, TurnUpsideDown: true},
+			expected: "level up, while raining, with the console upside down",
+		},
+		{
+			name:     "other trigger",
+			detail:   Detail{Trigger: Resource{Name: "three-critical-hits"}},
+			expected: "three critical hits",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := c.detail.String(); actual != c.expected {
+				t.Errorf("String() = %q, expected %q", actual, c.expected)
+			}
+		})
+	}
+}
+
+func TestEdgeCheck(t *testing.T) {
+	chain := loadChain(t, "eevee.json")
+	edges := map[string]Edge{}
+	for _, edge := range chain.EvolvesInto("eevee") {
+		edges[edge.To] = edge
+	}
+
+	cases := []struct {
+		name     string
+		into     string
+		state    State
+		ok       bool
+		expected []string
+	}{
+		{
+			name:  "item used",
+			into:  "vaporeon",
+			state: State{Level: 5, UsedItem: "water-stone"},
+			ok:    true,
+		},
+		{
+			name:     "wrong item",
+			into:     "vaporeon",
+			state:    State{Level: 5, UsedItem: "fire-stone"},
+			expected: []string{"use water-stone"},
+		},
+		{
+			name:  "happy during the day",
+			into:  "espeon",
+			state: State{Friendship: 200, Hour: 12},
+			ok:    true,
+		},
+		{
+			name:     "happy at night",
+			into:     "espeon",
+			state:    State{Friendship: 200, Hour: 22},
+			expected: []string{"during the day"},
+		},
+		{
+			name:     "unhappy at night",
+			into:     "espeon",
+			state:    State{Friendship: 70, Hour: 3},
+			expected: []string{"happiness 160+", "during the day"},
+		},
+		{
+			name:  "one of several ways",
+			into:  "leafeon",
+			state: State{UsedItem: "leaf-stone"},
+			ok:    true,
+		},
+		{
+			name:     "untracked condition",
+			into:     "sylveon",
+			state:    State{Level: 100, Friendship: 255},
+			expected: []string{"knows a fairy move", "affection 2+"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ok, missing := edges[c.into].Check(c.state)
+			if ok != c.ok {
+				t.Fatalf("Check = %v, expected %v", ok, c.ok)
+			}
+			if strings.Join(missing, "; ") != strings.Join(c.expected, "; ") {
+				t.Errorf("missing = %q, expected %q", missing, c.expected)
+			}
+		})
+	}
+}
+
+func TestEdgeCheck_Level(t *testing.T) {
+	edge := loadChain(t, "bulbasaur.json").EvolvesInto("bulbasaur")[0]
+
+	if ok, missing := edge.Check(State{Level: 15}); ok || len(missing) != 1 || missing[0] != "level 16" {
+		t.Errorf("Check at level 15 = %v, %q, expected level 16 missing", ok, missing)
+	}
+	if ok, _ := edge.Check(State{Level: 16}); !ok {
+		t.Error("Check at level 16 failed, expected it to pass")
+	}
+}
+
+func TestDetailUnmet_Trade(t *testing.T) {
+	detail := Detail{Trigger: Resource{Name: "trade"}, HeldItem: &Resource{Name: "metal-coat"}}
+
+	unmet := detail.Unmet(State{HeldItem: "metal-coat"})
+	if len(unmet) != 1 || unmet[0] != "trade" {
+		t.Errorf("Unmet = %q, expected only the trade", unmet)
+	}
+}
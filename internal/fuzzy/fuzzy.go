@@ -0,0 +1,73 @@
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+)
+
+// Distance returns the Levenshtein edit distance between a and b.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// Closest returns up to n candidates that are within a typo's reach of
+// name, nearest first. Ties are broken alphabetically.
+func Closest(name string, candidates []string, n int) []string {
+	// Allow roughly one edit for every three characters, but at least two
+	maxDistance := max(2, len([]rune(name))/3)
+
+	type match struct {
+		candidate string
+		distance  int
+	}
+	matches := []match{}
+	for _, candidate := range candidates {
+		d := Distance(name, candidate)
+		if d <= maxDistance {
+			matches = append(matches, match{candidate, d})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].candidate < matches[j].candidate
+	})
+
+	results := []string{}
+	for i := 0; i < len(matches) && i < n; i++ {
+		results = append(results, matches[i].candidate)
+	}
+	return results
+}
+
+// Search returns the candidates containing substr, in their original order.
+func Search(substr string, candidates []string) []string {
+	results := []string{}
+	for _, candidate := range candidates {
+		if strings.Contains(candidate, substr) {
+			results = append(results, candidate)
+		}
+	}
+	return results
+}
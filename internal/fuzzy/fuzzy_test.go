@@ -0,0 +1,63 @@
+package fuzzy
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "", b: "", expected: 0},
+		{a: "", b: "abc", expected: 3},
+		{a: "pikachu", b: "pikachu", expected: 0},
+		{a: "charmnder", b: "charmander", expected: 1},
+		{a: "palet-town-area", b: "pallet-town-area", expected: 1},
+		{a: "kitten", b: "sitting", expected: 3},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			actual := Distance(c.a, c.b)
+			if actual != c.expected {
+				t.Errorf("Distance(%q, %q) = %d, expected %d", c.a, c.b, actual, c.expected)
+			}
+		})
+	}
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"charmander", "charmeleon", "charizard", "pikachu", "squirtle"}
+
+	actual := Closest("charmnder", candidates, 3)
+	if len(actual) == 0 || actual[0] != "charmander" {
+		t.Errorf("expected charmander as the closest match, got %v", actual)
+	}
+
+	actual = Closest("zzzzzzzz", candidates, 3)
+	if len(actual) != 0 {
+		t.Errorf("expected no matches, got %v", actual)
+	}
+
+	actual = Closest("charmandr", candidates, 1)
+	if len(actual) != 1 {
+		t.Errorf("expected at most 1 match, got %v", actual)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	candidates := []string{"pallet-town-area", "viridian-forest-area", "cerulean-city-area"}
+
+	actual := Search("forest", candidates)
+	if len(actual) != 1 || actual[0] != "viridian-forest-area" {
+		t.Errorf("Search returned %v, expected [viridian-forest-area]", actual)
+	}
+
+	actual = Search("area", candidates)
+	if len(actual) != 3 {
+		t.Errorf("Search returned %d matches, expected 3", len(actual))
+	}
+}
@@ -0,0 +1,198 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteJSON writes v as indented JSON.
+func WriteJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// WriteYAML writes v as a YAML document. Values are converted through their
+// JSON encoding, so json struct tags decide the keys and their order.
+func WriteYAML(w io.Writer, v any) error {
+	dat, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(dat))
+	dec.UseNumber()
+	root, err := decodeNode(dec)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writeYAMLNode(&buf, root, 0)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// WriteTable writes rows as space-aligned columns under an upper-case header.
+func WriteTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	columns := make([]string, len(header))
+	for i, h := range header {
+		columns[i] = strings.ToUpper(h)
+	}
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// node is a decoded JSON value that, unlike map[string]any, remembers the
+// order of object keys.
+type node struct {
+	scalar string // already formatted for YAML; used when keys and items are nil
+	keys   []string
+	values []*node
+	items  []*node
+	isMap  bool
+	isList bool
+}
+
+func decodeNode(dec *json.Decoder) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			n := &node{isMap: true}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, keyTok.(string))
+				n.values = append(n.values, value)
+			}
+			_, err := dec.Token() // closing }
+			return n, err
+		}
+
+		n := &node{isList: true}
+		for dec.More() {
+			item, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+		}
+		_, err := dec.Token() // closing ]
+		return n, err
+	case string:
+		return &node{scalar: yamlString(t)}, nil
+	case json.Number:
+		return &node{scalar: t.String()}, nil
+	case bool:
+		return &node{scalar: strconv.FormatBool(t)}, nil
+	default:
+		return &node{scalar: "null"}, nil
+	}
+}
+
+func writeYAMLNode(buf *bytes.Buffer, n *node, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	switch {
+	case n.isMap:
+		if len(n.keys) == 0 {
+			buf.WriteString(pad + "{}\n")
+			return
+		}
+		for i, key := range n.keys {
+			buf.WriteString(pad + yamlString(key) + ":")
+			writeYAMLChild(buf, n.values[i], indent, false)
+		}
+	case n.isList:
+		if len(n.items) == 0 {
+			buf.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range n.items {
+			buf.WriteString(pad + "-")
+			writeYAMLChild(buf, item, indent, true)
+		}
+	default:
+		buf.WriteString(pad + n.scalar + "\n")
+	}
+}
+
+// writeYAMLChild writes the value that follows a "key:" or "-" marker,
+// inline when it is a scalar or empty collection and indented otherwise.
+// Collections inside a list start on the marker's line, as in "- name: x".
+func writeYAMLChild(buf *bytes.Buffer, n *node, indent int, listItem bool) {
+	switch {
+	case n.isMap && len(n.keys) == 0:
+		buf.WriteString(" {}\n")
+	case n.isList && len(n.items) == 0:
+		buf.WriteString(" []\n")
+	case (n.isMap || n.isList) && listItem:
+		var child bytes.Buffer
+		writeYAMLNode(&child, n, indent+1)
+		buf.WriteString(" ")
+		buf.Write(child.Bytes()[2*(indent+1):])
+	case n.isMap || n.isList:
+		buf.WriteString("\n")
+		writeYAMLNode(buf, n, indent+1)
+	default:
+		buf.WriteString(" " + n.scalar + "\n")
+	}
+}
+
+// yamlString returns s as a plain YAML scalar when that is unambiguous and
+// as a double-quoted string otherwise.
+func yamlString(s string) string {
+	if needsQuotes(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestWriteYAML(t *testing.T) {
+	type stat struct {
+		Name     string `json:"name"`
+		BaseStat int    `json:"base_stat"`
+	}
+	type pokemon struct {
+		Name     string   `json:"name"`
+		Height   int      `json:"height"`
+		Caught   bool     `json:"caught"`
+		Nickname string   `json:"nickname,omitempty"`
+		Types    []string `json:"types"`
+		Moves    []string `json:"moves"`
+		Stats    []stat   `json:"stats"`
+		Evolves  *string  `json:"evolves"`
+	}
+
+	cases := []struct {
+		input    any
+		expected string
+	}{
+		{
+			input: pokemon{
+				Name:   "pikachu",
+				Height: 4,
+				Caught: true,
+				Types:  []string{"electric"},
+				Moves:  []string{},
+				Stats:  []stat{{Name: "hp", BaseStat: 35}, {Name: "speed", BaseStat: 90}},
+			},
+			expected: `name: pikachu
+height: 4
+caught: true
+types:
+  - electric
+moves: []
+stats:
+  - name: hp
+    base_stat: 35
+  - name: speed
+    base_stat: 90
+evolves: null
+`,
+		},
+		{
+			input:    []string{"yes", "", "12", "mr-mime", "key: value", "- dash"},
+			expected: "- \"yes\"\n- \"\"\n- \"12\"\n- mr-mime\n- \"key: value\"\n- \"- dash\"\n",
+		},
+		{
+			input:    [][]int{{1, 2}, {}},
+			expected: "- - 1\n  - 2\n- []\n",
+		},
+		{
+			input:    map[string]any{},
+			expected: "{}\n",
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteYAML(&buf, c.input); err != nil {
+				t.Fatalf("WriteYAML returned unexpected error: %v", err)
+			}
+			if buf.String() != c.expected {
+				t.Errorf("WriteYAML output:\n%s\nexpected:\n%s", buf.String(), c.expected)
+			}
+		})
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteTable(&buf, []string{"name", "types"}, [][]string{
+		{"bulbasaur", "grass, poison"},
+		{"pikachu", "electric"},
+	})
+	if err != nil {
+		t.Fatalf("WriteTable returned unexpected error: %v", err)
+	}
+
+	expected := "NAME       TYPES\nbulbasaur  grass, poison\npikachu    electric\n"
+	if buf.String() != expected {
+		t.Errorf("WriteTable output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, map[string]int{"caught": 2}); err != nil {
+		t.Fatalf("WriteJSON returned unexpected error: %v", err)
+	}
+
+	expected := "{\n  \"caught\": 2\n}\n"
+	if buf.String() != expected {
+		t.Errorf("WriteJSON output %q, expected %q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,180 @@
+package sprite
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/see-why/Pokedex/internal/ansi"
+)
+
+// Mode selects how Render draws pixels.
+type Mode int
+
+const (
+	// TrueColor draws half blocks in 24-bit color.
+	TrueColor Mode = iota
+	// Color256 draws half blocks in the nearest of the xterm 256 colors.
+	Color256
+	// ASCII draws characters by brightness, for output without colors.
+	ASCII
+)
+
+// alphaThreshold is the alpha below which a pixel counts as transparent.
+const alphaThreshold = 0x8000
+
+// asciiRamp orders characters from dense to sparse, so dark pixels such as
+// sprite outlines come out as the heaviest characters. Spaces are kept for
+// transparent pixels.
+const asciiRamp = "@%#*+=-:."
+
+// Decode reads a PNG image.
+func Decode(r io.Reader) (image.Image, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("decoding sprite: %w", err)
+	}
+	return img, nil
+}
+
+// Render draws img to w, cropped to its visible pixels and scaled down to at
+// most maxWidth columns. Each row of text covers two rows of pixels.
+func Render(w io.Writer, img image.Image, mode Mode, maxWidth int) error {
+	img = scale(crop(img), maxWidth)
+	bounds := img.Bounds()
+
+	var out strings.Builder
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			top := img.At(x, y)
+			bottom := color.Color(color.Transparent)
+			if y+1 < bounds.Max.Y {
+				bottom = img.At(x, y+1)
+			}
+			out.WriteString(cell(top, bottom, mode))
+		}
+		if mode != ASCII {
+			out.WriteString("\x1b[0m")
+		}
+		out.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// cell draws one character covering the top and bottom pixels.
+func cell(top, bottom color.Color, mode Mode) string {
+	topVisible, bottomVisible := visible(top), visible(bottom)
+
+	if mode == ASCII {
+		lum, n := 0, 0
+		for _, c := range []color.Color{top, bottom} {
+			if visible(c) {
+				lum += luminance(c)
+				n++
+			}
+		}
+		if n == 0 {
+			return " "
+		}
+		return string(asciiRamp[(lum/n)*(len(asciiRamp)-1)/255])
+	}
+
+	switch {
+	case topVisible && bottomVisible:
+		return "\x1b[" + sgr(top, mode, false) + ";" + sgr(bottom, mode, true) + "m▀"
+	case topVisible:
+		return "\x1b[0;" + sgr(top, mode, false) + "m▀"
+	case bottomVisible:
+		return "\x1b[0;" + sgr(bottom, mode, false) + "m▄"
+	}
+	return "\x1b[0m "
+}
+
+// sgr returns the parameters that set c as the foreground or background.
+func sgr(c color.Color, mode Mode, background bool) string {
+	r, g, b := rgb(c)
+	layer := "38"
+	if background {
+		layer = "48"
+	}
+
+	if mode == Color256 {
+		return fmt.Sprintf("%s;5;%d", layer, ansi.Xterm256(r, g, b))
+	}
+	return fmt.Sprintf("%s;2;%d;%d;%d", layer, r, g, b)
+}
+
+func visible(c color.Color) bool {
+	_, _, _, a := c.RGBA()
+	return a >= alphaThreshold
+}
+
+// rgb returns the 8-bit color channels of c, undoing alpha premultiplication.
+func rgb(c color.Color) (int, int, int) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return int(n.R), int(n.G), int(n.B)
+}
+
+func luminance(c color.Color) int {
+	r, g, b := rgb(c)
+	return (299*r + 587*g + 114*b) / 1000
+}
+
+// crop trims the fully transparent border around img.
+func crop(img image.Image) image.Image {
+	bounds := img.Bounds()
+	visibleBounds := image.Rectangle{}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if visible(img.At(x, y)) {
+				visibleBounds = visibleBounds.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if visibleBounds.Empty() {
+		return img
+	}
+	return subImage(img, visibleBounds)
+}
+
+func subImage(img image.Image, r image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+
+	copied := image.NewNRGBA(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			copied.Set(x, y, img.At(x, y))
+		}
+	}
+	return copied
+}
+
+// scale shrinks img with nearest neighbor sampling so that it is at most
+// maxWidth pixels wide, keeping its aspect ratio. A maxWidth of 0 or less
+// leaves img unchanged.
+func scale(img image.Image, maxWidth int) image.Image {
+	bounds := img.Bounds()
+	if maxWidth <= 0 || bounds.Dx() <= maxWidth {
+		return img
+	}
+
+	height := max(1, bounds.Dy()*maxWidth/bounds.Dx())
+	scaled := image.NewNRGBA(image.Rect(0, 0, maxWidth, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < maxWidth; x++ {
+			srcX := bounds.Min.X + x*bounds.Dx()/maxWidth
+			srcY := bounds.Min.Y + y*bounds.Dy()/height
+			scaled.Set(x, y, img.At(srcX, srcY))
+		}
+	}
+	return scaled
+}
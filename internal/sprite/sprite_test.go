@@ -0,0 +1,92 @@
+package sprite
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadFixture(t *testing.T, name string) image.Image {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	img, err := Decode(f)
+	if err != nil {
+		t.Fatalf("Decode(%s) returned unexpected error: %v", name, err)
+	}
+	return img
+}
+
+func render(t *testing.T, img image.Image, mode Mode, maxWidth int) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := Render(&buf, img, mode, maxWidth); err != nil {
+		t.Fatalf("Render returned unexpected error: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRender_TrueColor(t *testing.T) {
+	actual := render(t, loadFixture(t, "tiny.png"), TrueColor, 0)
+
+	// The transparent border is cropped, leaving a 2x3 sprite drawn as two
+	// rows of half blocks
+	expected := "\x1b[38;2;255;0;0;48;2;0;0;255m▀\x1b[0;38;2;0;255;0m▀\x1b[0m\n" +
+		"\x1b[0;38;2;255;255;255m▀\x1b[0;38;2;0;0;0m▀\x1b[0m\n"
+	if actual != expected {
+		t.Errorf("Render = %q, expected %q", actual, expected)
+	}
+}
+
+func TestRender_Color256(t *testing.T) {
+	actual := render(t, loadFixture(t, "tiny.png"), Color256, 0)
+
+	expected := "\x1b[38;5;196;48;5;21m▀\x1b[0;38;5;46m▀\x1b[0m\n" +
+		"\x1b[0;38;5;231m▀\x1b[0;38;5;16m▀\x1b[0m\n"
+	if actual != expected {
+		t.Errorf("Render = %q, expected %q", actual, expected)
+	}
+}
+
+func TestRender_ASCII(t *testing.T) {
+	actual := render(t, loadFixture(t, "tiny.png"), ASCII, 0)
+
+	// Red and blue average to a dark cell, the lone green pixel is lighter,
+	// and white and black are the ends of the ramp
+	expected := "%+\n.@\n"
+	if actual != expected {
+		t.Errorf("Render = %q, expected %q", actual, expected)
+	}
+}
+
+func TestRender_ScalesPalettedImages(t *testing.T) {
+	actual := render(t, loadFixture(t, "stripes.png"), TrueColor, 4)
+
+	expected := strings.Repeat("\x1b[0;38;2;255;0;0m▀", 4) + "\x1b[0m\n"
+	if actual != expected {
+		t.Errorf("Render = %q, expected %q", actual, expected)
+	}
+}
+
+func TestRender_TransparentImage(t *testing.T) {
+	actual := render(t, loadFixture(t, "empty.png"), ASCII, 0)
+
+	if actual != "   \n   \n" {
+		t.Errorf("Render = %q, expected blank rows", actual)
+	}
+}
+
+func TestDecode_InvalidPNG(t *testing.T) {
+	if _, err := Decode(strings.NewReader("not a png")); err == nil {
+		t.Error("expected an error decoding invalid data")
+	}
+}
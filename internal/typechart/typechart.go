@@ -0,0 +1,142 @@
+package typechart
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Relations are the damage relations of one type, as listed by PokeAPI's
+// /type endpoint: the types it hits for double, half or no damage, and the
+// types that hit it for double, half or no damage.
+type Relations struct {
+	DoubleDamageTo   []string
+	HalfDamageTo     []string
+	NoDamageTo       []string
+	DoubleDamageFrom []string
+	HalfDamageFrom   []string
+	NoDamageFrom     []string
+}
+
+// Chart holds the damage relations of the types it knows, by type name.
+// Only the types on the defending side need to be known to compute
+// multipliers.
+type Chart map[string]Relations
+
+// Multiplier is how much damage a move of the attacking type does to a
+// Pokemon of the defending types: 0, 0.25, 0.5, 1, 2 or 4 for a dual type.
+func (c Chart) Multiplier(attacking string, defending ...string) (float64, error) {
+	multiplier := 1.0
+	for _, name := range defending {
+		relations, ok := c[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown type %q", name)
+		}
+		multiplier *= relations.from(attacking)
+	}
+	return multiplier, nil
+}
+
+// Defense returns the multipliers of the attacking types that do not deal
+// normal damage to a Pokemon of the defending types.
+func (c Chart) Defense(defending ...string) (map[string]float64, error) {
+	attackers := map[string]bool{}
+	for _, name := range defending {
+		relations, ok := c[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q", name)
+		}
+		for _, list := range [][]string{relations.DoubleDamageFrom, relations.HalfDamageFrom, relations.NoDamageFrom} {
+			for _, attacker := range list {
+				attackers[attacker] = true
+			}
+		}
+	}
+
+	multipliers := map[string]float64{}
+	for attacker := range attackers {
+		multiplier, err := c.Multiplier(attacker, defending...)
+		if err != nil {
+			return nil, err
+		}
+		if multiplier != 1 {
+			multipliers[attacker] = multiplier
+		}
+	}
+	return multipliers, nil
+}
+
+// Offense returns the multipliers of a move of the attacking type against
+// the single types it does not deal normal damage to.
+func (c Chart) Offense(attacking string) (map[string]float64, error) {
+	relations, ok := c[attacking]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", attacking)
+	}
+
+	multipliers := map[string]float64{}
+	for _, name := range relations.DoubleDamageTo {
+		multipliers[name] = 2
+	}
+	for _, name := range relations.HalfDamageTo {
+		multipliers[name] = 0.5
+	}
+	for _, name := range relations.NoDamageTo {
+		multipliers[name] = 0
+	}
+	return multipliers, nil
+}
+
+// from is the multiplier of a move of the attacking type against this type
+// alone.
+func (r Relations) from(attacking string) float64 {
+	switch {
+	case contains(r.NoDamageFrom, attacking):
+		return 0
+	case contains(r.DoubleDamageFrom, attacking):
+		return 2
+	case contains(r.HalfDamageFrom, attacking):
+		return 0.5
+	default:
+		return 1
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Group is the types that share a multiplier.
+type Group struct {
+	Multiplier float64  `json:"multiplier"`
+	Types      []string `json:"types"`
+}
+
+// Groups gathers multipliers by value, highest multiplier first and types in
+// alphabetical order.
+func Groups(multipliers map[string]float64) []Group {
+	byMultiplier := map[float64][]string{}
+	for name, multiplier := range multipliers {
+		byMultiplier[multiplier] = append(byMultiplier[multiplier], name)
+	}
+
+	groups := []Group{}
+	for multiplier, names := range byMultiplier {
+		sort.Strings(names)
+		groups = append(groups, Group{Multiplier: multiplier, Types: names})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Multiplier > groups[j].Multiplier
+	})
+	return groups
+}
+
+// Format writes a multiplier the way the games describe it, e.g. "0.25x".
+func Format(multiplier float64) string {
+	return strconv.FormatFloat(multiplier, 'f', -1, 64) + "x"
+}
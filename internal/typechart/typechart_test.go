@@ -0,0 +1,120 @@
+package typechart
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testChart holds a few real type relations, trimmed to the types the tests
+// use.
+var testChart = Chart{
+	"electric": {
+		DoubleDamageTo:   []string{"water", "flying"},
+		HalfDamageTo:     []string{"electric", "grass", "dragon"},
+		NoDamageTo:       []string{"ground"},
+		DoubleDamageFrom: []string{"ground"},
+		HalfDamageFrom:   []string{"electric", "flying", "steel"},
+	},
+	"water": {
+		DoubleDamageFrom: []string{"electric", "grass"},
+		HalfDamageFrom:   []string{"fire", "water", "ice", "steel"},
+	},
+	"flying": {
+		DoubleDamageFrom: []string{"electric", "ice", "rock"},
+		HalfDamageFrom:   []string{"grass", "fighting", "bug"},
+		NoDamageFrom:     []string{"ground"},
+	},
+	"ground": {
+		DoubleDamageFrom: []string{"water", "grass", "ice"},
+		HalfDamageFrom:   []string{"poison", "rock"},
+		NoDamageFrom:     []string{"electric"},
+	},
+	"steel": {
+		DoubleDamageFrom: []string{"fire", "fighting", "ground"},
+		HalfDamageFrom:   []string{"normal", "grass", "ice", "flying", "psychic", "bug", "rock", "dragon", "steel", "fairy"},
+		NoDamageFrom:     []string{"poison"},
+	},
+	"bug": {
+		DoubleDamageFrom: []string{"fire", "flying", "rock"},
+		HalfDamageFrom:   []string{"grass", "fighting", "ground"},
+	},
+}
+
+func TestMultiplier(t *testing.T) {
+	cases := []struct {
+		attacking string
+		defending []string
+		expected  float64
+	}{
+		{attacking: "electric", defending: []string{"water"}, expected: 2},
+		{attacking: "electric", defending: []string{"water", "flying"}, expected: 4},
+		{attacking: "electric", defending: []string{"ground", "flying"}, expected: 0},
+		{attacking: "grass", defending: []string{"steel", "bug"}, expected: 0.25},
+		{attacking: "fire", defending: []string{"steel", "bug"}, expected: 4},
+		{attacking: "ground", defending: []string{"steel", "bug"}, expected: 1},
+		{attacking: "normal", defending: []string{"water"}, expected: 1},
+		{attacking: "steel", defending: []string{"electric"}, expected: 0.5},
+	}
+
+	for _, c := range cases {
+		actual, err := testChart.Multiplier(c.attacking, c.defending...)
+		if err != nil {
+			t.Fatalf("Multiplier(%s, %v) returned unexpected error: %v", c.attacking, c.defending, err)
+		}
+		if actual != c.expected {
+			t.Errorf("Multiplier(%s, %v) = %v, expected %v", c.attacking, c.defending, actual, c.expected)
+		}
+	}
+}
+
+func TestMultiplier_UnknownType(t *testing.T) {
+	if _, err := testChart.Multiplier("fire", "shadow"); err == nil {
+		t.Error("expected an error for an unknown defending type")
+	}
+}
+
+func TestDefense_DualType(t *testing.T) {
+	actual, err := testChart.Defense("water", "flying")
+	if err != nil {
+		t.Fatalf("Defense returned unexpected error: %v", err)
+	}
+
+	expected := map[string]float64{
+		"electric": 4,
+		"rock":     2,
+		"fire":     0.5,
+		"water":    0.5,
+		"steel":    0.5,
+		"fighting": 0.5,
+		"bug":      0.5,
+		"ground":   0,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Defense(water, flying) = %v, expected %v", actual, expected)
+	}
+}
+
+func TestOffense(t *testing.T) {
+	actual, err := testChart.Offense("electric")
+	if err != nil {
+		t.Fatalf("Offense returned unexpected error: %v", err)
+	}
+
+	expected := []Group{
+		{Multiplier: 2, Types: []string{"flying", "water"}},
+		{Multiplier: 0.5, Types: []string{"dragon", "electric", "grass"}},
+		{Multiplier: 0, Types: []string{"ground"}},
+	}
+	if groups := Groups(actual); !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Groups(Offense(electric)) = %v, expected %v", groups, expected)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := map[float64]string{0: "0x", 0.25: "0.25x", 0.5: "0.5x", 1: "1x", 2: "2x", 4: "4x"}
+	for multiplier, expected := range cases {
+		if actual := Format(multiplier); actual != expected {
+			t.Errorf("Format(%v) = %q, expected %q", multiplier, actual, expected)
+		}
+	}
+}
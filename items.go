@@ -0,0 +1,303 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// listCursor is where the next page of a list starts, so that running its
+// command again shows the following page, as map does.
+type listCursor struct {
+	offset int
+	limit  int
+	// count is the length of the list when the last page was shown.
+	count int
+	// previous is where the page before the one shown last starts, or -1
+	// when that was the first page, so --previous can go back as mapb does.
+	previous int
+}
+
+// namesPage is one page of a list of names, such as the items or berries.
+type namesPage struct {
+	Page       int      `json:"page"`
+	TotalPages int      `json:"total_pages"`
+	Count      int      `json:"count"`
+	Names      []string `json:"names"`
+	// kind names one entry, e.g. "item", for the table header.
+	kind string
+}
+
+func (r namesPage) writeText(w io.Writer) {
+	for _, name := range r.Names {
+		fmt.Fprintln(w, name)
+	}
+	fmt.Fprintf(w, "page %d of %d\n", r.Page, r.TotalPages)
+}
+
+func (r namesPage) tableRows() ([]string, [][]string) {
+	return []string{r.kind}, nameRows(r.Names)
+}
+
+// nextListPage works out which page of the named list to show: the one the
+// flags ask for, the one before the page shown last with --previous, or the
+// one after it. A non-nil stop is shown instead when there is no such page,
+// so there is nothing to fetch.
+func nextListPage(cfg *config, list string, flags commandFlags) (offset, limit int, stop commandResult, err error) {
+	cursor, ok := cfg.listCursors[list]
+	if !ok {
+		cursor = listCursor{offset: 0, limit: defaultPageLimit, previous: -1}
+	}
+
+	if flags.has("previous") {
+		if flags.has("page") || flags.has("offset") {
+			return 0, 0, nil, fmt.Errorf("--previous cannot be used with --page or --offset")
+		}
+		if cursor.previous < 0 {
+			return 0, 0, firstPageMessage, nil
+		}
+		offset, limit, err = applyPageFlags(flags, cursor.previous, cursor.limit)
+		return offset, limit, nil, err
+	}
+
+	if ok && len(flags) == 0 && cursor.offset >= cursor.count {
+		return 0, 0, lastPageMessage, nil
+	}
+	offset, limit, err = applyPageFlags(flags, cursor.offset, cursor.limit)
+	return offset, limit, nil, err
+}
+
+var (
+	lastPageMessage  = messageResult{Message: "you're on the last page"}
+	firstPageMessage = messageResult{Message: "you're on the first page"}
+)
+
+// showListPage returns the page of names at offset and moves the cursor of
+// list past it. count is the length of the whole list.
+func showListPage(cfg *config, list, kind string, names []string, count, offset, limit int) (commandResult, error) {
+	if offset > 0 && offset >= count {
+		return lastPageMessage, nil
+	}
+	if cfg.listCursors == nil {
+		cfg.listCursors = map[string]listCursor{}
+	}
+	previous := -1
+	if offset > 0 {
+		previous = max(0, offset-limit)
+	}
+	cfg.listCursors[list] = listCursor{offset: offset + limit, limit: limit, count: count, previous: previous}
+
+	return namesPage{
+		Page:       offset/limit + 1,
+		TotalPages: max(1, (count+limit-1)/limit),
+		Count:      count,
+		Names:      names,
+		kind:       kind,
+	}, nil
+}
+
+func commandItems(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	if len(args) > 0 {
+		return itemCategoryPage(cfg, flags, args[0])
+	}
+
+	offset, limit, stop, err := nextListPage(cfg, "items", flags)
+	if err != nil {
+		return nil, err
+	}
+	if stop != nil {
+		return stop, nil
+	}
+	return resourceListPage(cfg, "items", "item", "item", offset, limit)
+}
+
+// itemCategoryPage pages through the items of one category. PokeAPI returns
+// a category's items all at once, so they are paged here.
+func itemCategoryPage(cfg *config, flags commandFlags, categoryName string) (commandResult, error) {
+	category, err := getItemCategory(cfg, categoryName)
+	if errors.Is(err, errNotFound) {
+		return nil, notFoundError(cfg, "item-category", categoryName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	list := "items/" + category.Name
+	offset, limit, stop, err := nextListPage(cfg, list, flags)
+	if err != nil {
+		return nil, err
+	}
+	if stop != nil {
+		return stop, nil
+	}
+
+	names := []string{}
+	for i := offset; i < min(offset+limit, len(category.Items)); i++ {
+		names = append(names, category.Items[i].Name)
+	}
+	return showListPage(cfg, list, "item", names, len(category.Items), offset, limit)
+}
+
+func commandBerries(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	offset, limit, stop, err := nextListPage(cfg, "berries", flags)
+	if err != nil {
+		return nil, err
+	}
+	if stop != nil {
+		return stop, nil
+	}
+	return resourceListPage(cfg, "berries", "berry", "berry", offset, limit)
+}
+
+// resourceListPage fetches a page of a PokeAPI resource list and shows it as
+// a page of list.
+func resourceListPage(cfg *config, list, resource, kind string, offset, limit int) (commandResult, error) {
+	page, err := fetchResource[namedAPIResourceList](cfg, listPageURL(cfg, resource, offset, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, result := range page.Results {
+		names = append(names, result.Name)
+	}
+	return showListPage(cfg, list, kind, names, page.Count, offset, limit)
+}
+
+type itemResult struct {
+	Name        string   `json:"name"`
+	Category    string   `json:"category"`
+	Cost        int      `json:"cost"`
+	Effect      string   `json:"effect,omitempty"`
+	FlingPower  *int     `json:"fling_power"`
+	FlingEffect string   `json:"fling_effect,omitempty"`
+	Attributes  []string `json:"attributes"`
+}
+
+func newItemResult(cfg *config, item itemResp) itemResult {
+	result := itemResult{
+		Name:       item.Name,
+		Category:   item.Category.Name,
+		Cost:       item.Cost,
+		Effect:     selectEffect(item.EffectEntries, cfg.currentSettings().Language),
+		FlingPower: item.FlingPower,
+		Attributes: resourceNames(item.Attributes),
+	}
+	if item.FlingEffect != nil {
+		result.FlingEffect = item.FlingEffect.Name
+	}
+	return result
+}
+
+func (r itemResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, r.Name)
+	r.writeDetails(w)
+}
+
+// writeDetails writes everything but the name, for use below a berry.
+func (r itemResult) writeDetails(w io.Writer) {
+	fmt.Fprintf(w, "Category: %s\n", r.Category)
+	fmt.Fprintf(w, "Cost: %d\n", r.Cost)
+	if r.Effect != "" {
+		fmt.Fprintf(w, "Effect: %s\n", r.Effect)
+	}
+	if r.FlingPower != nil {
+		fmt.Fprintf(w, "Fling power: %d\n", *r.FlingPower)
+	}
+	if r.FlingEffect != "" {
+		fmt.Fprintf(w, "Fling effect: %s\n", r.FlingEffect)
+	}
+	if len(r.Attributes) > 0 {
+		fmt.Fprintf(w, "Attributes: %s\n", strings.Join(r.Attributes, ", "))
+	}
+}
+
+func commandItem(cfg *config, _ commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide an item name")
+	}
+
+	item, err := getItem(cfg, args[0])
+	if errors.Is(err, errNotFound) {
+		return nil, notFoundError(cfg, "item", args[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newItemResult(cfg, item), nil
+}
+
+type berryResult struct {
+	Name             string        `json:"name"`
+	NaturalGiftType  string        `json:"natural_gift_type"`
+	NaturalGiftPower int           `json:"natural_gift_power"`
+	Firmness         string        `json:"firmness"`
+	GrowthTime       int           `json:"growth_time"`
+	MaxHarvest       int           `json:"max_harvest"`
+	Flavors          []berryFlavor `json:"flavors"`
+	Item             itemResult    `json:"item"`
+}
+
+type berryFlavor struct {
+	Flavor  string `json:"flavor"`
+	Potency int    `json:"potency"`
+}
+
+func (r berryResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s (%s)\n", r.Name, r.Item.Name)
+	r.Item.writeDetails(w)
+	fmt.Fprintf(w, "Natural gift: %s, power %d\n", r.NaturalGiftType, r.NaturalGiftPower)
+	fmt.Fprintf(w, "Firmness: %s\n", r.Firmness)
+	fmt.Fprintf(w, "Growth time: %d hours per stage\n", r.GrowthTime)
+	fmt.Fprintf(w, "Max harvest: %d\n", r.MaxHarvest)
+	if len(r.Flavors) > 0 {
+		flavors := make([]string, 0, len(r.Flavors))
+		for _, flavor := range r.Flavors {
+			flavors = append(flavors, flavor.Flavor+" "+strconv.Itoa(flavor.Potency))
+		}
+		fmt.Fprintf(w, "Flavors: %s\n", strings.Join(flavors, ", "))
+	}
+}
+
+func commandBerry(cfg *config, _ commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide a berry name")
+	}
+
+	// Berries are named without the "-berry" their items have
+	name := strings.TrimSuffix(args[0], "-berry")
+	berry, err := getBerry(cfg, name)
+	if errors.Is(err, errNotFound) {
+		return nil, notFoundError(cfg, "berry", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := getItem(cfg, berry.Item.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := berryResult{
+		Name:             berry.Name,
+		NaturalGiftType:  berry.NaturalGiftType.Name,
+		NaturalGiftPower: berry.NaturalGiftPower,
+		Firmness:         berry.Firmness.Name,
+		GrowthTime:       berry.GrowthTime,
+		MaxHarvest:       berry.MaxHarvest,
+		Flavors:          []berryFlavor{},
+		Item:             newItemResult(cfg, item),
+	}
+	// Only the flavors the berry has are worth showing
+	for _, flavor := range berry.Flavors {
+		if flavor.Potency > 0 {
+			result.Flavors = append(result.Flavors, berryFlavor{Flavor: flavor.Flavor.Name, Potency: flavor.Potency})
+		}
+	}
+
+	return result, nil
+}
@@ -1,86 +1,134 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"math/rand"
-	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/chzyer/readline"
+	"github.com/see-why/Pokedex/internal/fuzzy"
 	"github.com/see-why/Pokedex/internal/pokecache"
 )
 
 func main() {
+	settings, args, err := loadSettings(os.Args[1:], os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitOK)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
 	config := &config{
-		pokeapiClient:       pokecache.NewCache(5 * time.Minute),
-		nextLocationURL:     "https://pokeapi.co/api/v2/location-area",
+		pokeapiClient:       pokecache.NewCache(settings.CacheInterval),
+		nextLocationURL:     settings.APIBaseURL + "/location-area",
 		previousLocationURL: nil,
-		caughtPokemon:       make(map[string]Pokemon),
+		caughtPokemon:       make(map[string]ownedPokemon),
+		outputFormat:        settings.Output,
+		settings:            settings,
+		out:                 os.Stdout,
+		diag:                os.Stderr,
 	}
 
-	// Create readline instance with command history
-	rl, err := readline.New("Pokedex > ")
+	// Aliases are available in every mode, so scripts can use them too
+	aliasesPath := ""
+	if dir, err := configDir(); err == nil {
+		aliasesPath = filepath.Join(dir, "aliases")
+	}
+	config.aliases, err = loadAliases(aliasesPath)
 	if err != nil {
-		fmt.Printf("Error creating readline: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error loading aliases: %v\n", err)
 	}
-	defer rl.Close()
-
-	for {
-		input, err := rl.Readline()
-		if err != nil {
-			// Handle EOF (Ctrl+C, Ctrl+D) or other errors
-			if err == readline.ErrInterrupt {
-				fmt.Println("\nClosing the Pokedex... Goodbye!")
-				break
-			} else if err == io.EOF {
-				fmt.Println("\nClosing the Pokedex... Goodbye!")
-				break
-			} else {
-				fmt.Printf("Error reading input: %v\n", err)
-				continue
-			}
-		}
 
-		words := cleanInput(input)
-		if len(words) == 0 {
-			continue
-		}
-
-		commandName := words[0]
-		args := []string{}
-		if len(words) > 1 {
-			args = words[1:]
-		}
-
-		// Look up command in registry
-		commands := getCommands()
-		if command, exists := commands[commandName]; exists {
-			err := command.callback(config, args...)
-			if err != nil {
-				fmt.Printf("Error: %v\n", err)
-			}
-		} else {
-			fmt.Println("Unknown command")
+	switch {
+	case len(args) > 0 && args[0] == "run":
+		// pokedex run script.txt
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "Usage: pokedex run <script>")
+			os.Exit(exitUsage)
 		}
+		os.Exit(runScriptFile(config, args[1]))
+	case len(args) > 0:
+		// pokedex catch pikachu
+		os.Exit(runOneShot(config, args))
+	case !readline.IsTerminal(int(os.Stdin.Fd())):
+		// echo "map" | pokedex
+		os.Exit(runScript(config, os.Stdin))
 	}
+
+	startRepl(config)
 }
 
 type config struct {
 	pokeapiClient       pokecache.Cache
 	nextLocationURL     string
 	previousLocationURL *string
-	caughtPokemon       map[string]Pokemon
+	caughtPokemon       map[string]ownedPokemon
+	// seenPokemon holds the names of the Pokemon met while exploring or that
+	// escaped a Pokeball, caught or not.
+	seenPokemon     map[string]bool
+	currentRegion   string
+	currentLocation string
+	currentArea     string
+	history         *replHistory
+	aliases         *userAliases
+	outputFormat    outputFormat
+	settings        *settings
+	out             io.Writer
+	diag            io.Writer
+	rollPercent     func() int
+	ballsThrown     int
+	// listCursors holds where the next page of each paginated list other
+	// than map starts, by list name.
+	listCursors map[string]listCursor
+	// pendingRequests counts the PokeAPI requests in progress, and
+	// onRequestsChanged, when set, is called as it changes.
+	pendingRequests   atomic.Int32
+	onRequestsChanged func()
+	// now returns the current time and choose asks the user to pick one of
+	// options, returning its index. Both are replaced in tests; choose is
+	// nil when there is no one to ask.
+	now    func() time.Time
+	choose func(question string, options []string) (int, error)
 }
 
 type cliCommand struct {
 	name        string
 	description string
-	callback    func(*config, ...string) error
+	category    commandCategory
+	args        []commandArg
+	flags       []commandFlag
+	examples    []string
+	aliases     []string
+	callback    func(*config, commandFlags, ...string) (commandResult, error)
+}
+
+// commandArg describes a positional argument. Arguments marked as
+// identifiers name PokeAPI resources and are normalized with apiName.
+type commandArg struct {
+	name        string
+	description string
+	optional    bool
+	identifier  bool
+	// variadic marks a last argument that takes all the remaining words.
+	variadic bool
+}
+
+// commandFlag describes a flag a command accepts. value names the flag's
+// argument in usage text and is empty for flags that take no value.
+type commandFlag struct {
+	name        string
+	value       string
+	description string
 }
 
 func getCommands() map[string]cliCommand {
@@ -88,373 +136,888 @@ func getCommands() map[string]cliCommand {
 		"exit": {
 			name:        "exit",
 			description: "Exit the Pokedex",
+			category:    categorySystem,
 			callback:    commandExit,
 		},
 		"help": {
 			name:        "help",
 			description: "Displays a help message",
-			callback:    commandHelp,
+			category:    categorySystem,
+			args: []commandArg{
+				{name: "command", description: "Command to show details for", optional: true, identifier: true},
+			},
+			examples: []string{"help", "help explore"},
+			callback: commandHelp,
 		},
 		"map": {
 			name:        "map",
 			description: "Displays the names of 20 location areas",
-			callback:    commandMap,
+			category:    categoryNavigation,
+			flags: []commandFlag{
+				{name: "page", value: "N", description: "Jump to page N"},
+				{name: "limit", value: "N", description: "Show N location areas per page"},
+				{name: "offset", value: "N", description: "Start the page at the Nth location area"},
+			},
+			examples: []string{"map", "map --page 3", "map --limit 50 --offset 100"},
+			callback: commandMap,
 		},
 		"mapb": {
 			name:        "mapb",
 			description: "Displays the previous 20 location areas",
+			category:    categoryNavigation,
 			callback:    commandMapb,
 		},
+		"regions": {
+			name:        "regions",
+			description: "Lists all regions",
+			category:    categoryNavigation,
+			callback:    commandRegions,
+		},
+		"region": {
+			name:        "region",
+			description: "Selects a region and lists its locations",
+			category:    categoryNavigation,
+			args: []commandArg{
+				{name: "region", description: "Region to select", identifier: true},
+			},
+			examples: []string{"region kanto"},
+			callback: commandRegion,
+		},
+		"locations": {
+			name:        "locations",
+			description: "Lists the locations in the current region",
+			category:    categoryNavigation,
+			callback:    commandLocations,
+		},
+		"areas": {
+			name:        "areas",
+			description: "Lists the areas of a location",
+			category:    categoryNavigation,
+			args: []commandArg{
+				{name: "location", description: "Location to list; defaults to the current location", optional: true, identifier: true},
+			},
+			examples: []string{"areas pallet-town"},
+			callback: commandAreas,
+		},
 		"explore": {
 			name:        "explore",
-			description: "Explore a location area",
-			callback:    commandExplore,
+			description: "Explore a location area (defaults to the current area)",
+			category:    categoryNavigation,
+			args: []commandArg{
+				{name: "location-area", description: "Location area to explore; defaults to the current area", optional: true, identifier: true},
+			},
+			examples: []string{"explore pallet-town-area", "explore"},
+			aliases:  []string{"e"},
+			callback: commandExplore,
 		},
 		"catch": {
 			name:        "catch",
 			description: "Attempt to catch a Pokemon",
-			callback:    commandCatch,
+			category:    categoryCollection,
+			args: []commandArg{
+				{name: "pokemon", description: "Pokemon to throw a Pokeball at", identifier: true},
+			},
+			flags: []commandFlag{
+				{name: "nickname", value: "NAME", description: "Nickname to give the Pokemon if it is caught"},
+			},
+			examples: []string{"catch pikachu", `catch pikachu --nickname "Sparky Jr"`},
+			aliases:  []string{"c"},
+			callback: commandCatch,
 		},
 		"inspect": {
 			name:        "inspect",
 			description: "Inspect a caught Pokemon",
-			callback:    commandInspect,
+			category:    categoryCollection,
+			args: []commandArg{
+				{name: "pokemon", description: "Name or nickname of the caught Pokemon to inspect"},
+			},
+			flags: []commandFlag{
+				{name: "sprite", description: "Draw the Pokemon's sprite above its details"},
+				{name: "full", description: "Add the species details and Pokedex entry"},
+			},
+			examples: []string{"inspect pikachu", "inspect Sparky", "inspect pikachu --sprite", "inspect pikachu --full"},
+			aliases:  []string{"i"},
+			callback: commandInspect,
+		},
+		"species": {
+			name:        "species",
+			description: "Shows the species details and Pokedex entry of a Pokemon",
+			category:    categoryCollection,
+			args: []commandArg{
+				{name: "pokemon", description: "Species or Pokemon to describe", identifier: true},
+			},
+			flags: []commandFlag{
+				{name: "version", value: "GAME", description: "Show the Pokedex entry from this game, e.g. red"},
+				{name: "language", value: "LANG", description: "Show the genus and Pokedex entry in this language, e.g. fr"},
+			},
+			examples: []string{"species pikachu", "species pikachu --version red", "species eevee --language fr"},
+			callback: commandSpecies,
+		},
+		"evolutions": {
+			name:        "evolutions",
+			description: "Shows the evolution tree of a Pokemon",
+			category:    categoryCollection,
+			args: []commandArg{
+				{name: "pokemon", description: "Species or Pokemon whose evolutions to show", identifier: true},
+			},
+			examples: []string{"evolutions bulbasaur", "evolutions eevee"},
+			callback: commandEvolutions,
+		},
+		"evolve": {
+			name:        "evolve",
+			description: "Evolves a caught Pokemon that meets the conditions",
+			category:    categoryCollection,
+			args: []commandArg{
+				{name: "pokemon", description: "Name or nickname of the caught Pokemon"},
+			},
+			flags: []commandFlag{
+				{name: "item", value: "ITEM", description: "Use an item on it, e.g. thunder-stone"},
+				{name: "into", value: "SPECIES", description: "Choose the evolution when there are several"},
+			},
+			examples: []string{"evolve bulbasaur", "evolve pikachu --item thunder-stone", "evolve eevee --item water-stone --into vaporeon"},
+			callback: commandEvolve,
+		},
+		"train": {
+			name:        "train",
+			description: "Levels up a caught Pokemon, raising its friendship",
+			category:    categoryCollection,
+			args: []commandArg{
+				{name: "pokemon", description: "Name or nickname of the caught Pokemon"},
+			},
+			flags: []commandFlag{
+				{name: "levels", value: "N", description: "Number of levels to gain (default 1)"},
+			},
+			examples: []string{"train bulbasaur", "train bulbasaur --levels 11"},
+			callback: commandTrain,
+		},
+		"give": {
+			name:        "give",
+			description: "Gives a caught Pokemon an item to hold, or takes it back",
+			category:    categoryCollection,
+			args: []commandArg{
+				{name: "pokemon", description: "Name or nickname of the caught Pokemon"},
+				{name: "item", description: "Item to hold; omit it to take the held item", optional: true, identifier: true},
+			},
+			examples: []string{"give onix metal-coat", "give onix"},
+			callback: commandGive,
+		},
+		"types": {
+			name:        "types",
+			description: "Shows what a type is strong and weak against",
+			category:    categoryBattle,
+			args: []commandArg{
+				{name: "type", description: "Type to show, e.g. fire", identifier: true},
+				{name: "second-type", description: "Second type, to show a dual type's weaknesses", optional: true, identifier: true},
+			},
+			examples: []string{"types fire", "types water flying"},
+			callback: commandTypes,
+		},
+		"matchup": {
+			name:        "matchup",
+			description: "Compares the type effectiveness of two Pokemon",
+			category:    categoryBattle,
+			args: []commandArg{
+				{name: "pokemon", description: "First Pokemon, caught or not"},
+				{name: "vs", description: "The word vs, for readability", optional: true},
+				{name: "opponent", description: "Second Pokemon, caught or not"},
+			},
+			examples: []string{"matchup pikachu vs pidgey", "matchup sparky gyarados"},
+			callback: commandMatchup,
+		},
+		"compare": {
+			name:        "compare",
+			description: "Compares the base stats, size and type matchups of Pokemon side by side",
+			category:    categoryBattle,
+			args: []commandArg{
+				{name: "pokemon", description: "First Pokemon, caught or not"},
+				{name: "other", description: "Second Pokemon, caught or not"},
+				{name: "more", description: "More Pokemon to compare", optional: true, variadic: true},
+			},
+			examples: []string{"compare pikachu raichu", "compare sparky pidgey raichu"},
+			callback: commandCompare,
+		},
+		"moves": {
+			name:        "moves",
+			description: "Lists the moves a Pokemon learns",
+			category:    categoryBattle,
+			args: []commandArg{
+				{name: "pokemon", description: "Pokemon whose moves to list, caught or not"},
+			},
+			flags: []commandFlag{
+				{name: "method", value: "METHOD", description: "Only moves learned this way: level-up, machine, egg or tutor"},
+				{name: "version-group", value: "GROUP", description: "Moves learned in these games, e.g. red-blue (default: the latest)"},
+			},
+			examples: []string{"moves pikachu", "moves pikachu --method level-up", "moves bulbasaur --version-group red-blue"},
+			callback: commandMoves,
+		},
+		"move": {
+			name:        "move",
+			description: "Shows the power, accuracy, PP, type and effect of a move",
+			category:    categoryBattle,
+			args: []commandArg{
+				{name: "move", description: "Move to describe", identifier: true},
+			},
+			examples: []string{"move thunderbolt", "move swords-dance"},
+			callback: commandMove,
+		},
+		"ability": {
+			name:        "ability",
+			description: "Shows the effect of an ability and the Pokemon that have it",
+			category:    categoryBattle,
+			args: []commandArg{
+				{name: "ability", description: "Ability to describe", identifier: true},
+			},
+			examples: []string{"ability static", "ability lightning-rod"},
+			callback: commandAbility,
+		},
+		"items": {
+			name:        "items",
+			description: "Lists items, 20 at a time, optionally of one category",
+			category:    categoryCollection,
+			args: []commandArg{
+				{name: "category", description: "Item category, e.g. healing or standard-balls", optional: true, identifier: true},
+			},
+			flags: []commandFlag{
+				{name: "page", value: "N", description: "Jump to page N"},
+				{name: "limit", value: "N", description: "Show N items per page"},
+				{name: "offset", value: "N", description: "Start the page at the Nth item"},
+				{name: "previous", description: "Show the page before the one shown last"},
+			},
+			examples: []string{"items", "items healing", "items --page 3", "items --previous"},
+			callback: commandItems,
+		},
+		"item": {
+			name:        "item",
+			description: "Shows the cost, effect and fling data of an item",
+			category:    categoryCollection,
+			args: []commandArg{
+				{name: "item", description: "Item to describe", identifier: true},
+			},
+			examples: []string{"item potion", "item thunder-stone"},
+			callback: commandItem,
+		},
+		"berries": {
+			name:        "berries",
+			description: "Lists berries, 20 at a time",
+			category:    categoryCollection,
+			flags: []commandFlag{
+				{name: "page", value: "N", description: "Jump to page N"},
+				{name: "limit", value: "N", description: "Show N berries per page"},
+				{name: "offset", value: "N", description: "Start the page at the Nth berry"},
+				{name: "previous", description: "Show the page before the one shown last"},
+			},
+			examples: []string{"berries", "berries --limit 64", "berries --previous"},
+			callback: commandBerries,
+		},
+		"berry": {
+			name:        "berry",
+			description: "Shows the natural gift, flavors and item data of a berry",
+			category:    categoryCollection,
+			args: []commandArg{
+				{name: "berry", description: "Berry to describe, e.g. cheri or cheri-berry", identifier: true},
+			},
+			examples: []string{"berry cheri", "berry sitrus-berry"},
+			callback: commandBerry,
 		},
 		"pokedex": {
 			name:        "pokedex",
-			description: "Show all caught Pokemon",
-			callback:    commandPokedex,
+			description: "Show all caught Pokemon, or progress through a regional Pokedex",
+			category:    categoryCollection,
+			aliases:     []string{"ls"},
+			args: []commandArg{
+				{name: "where", description: "Start a query over caught Pokemon", optional: true},
+				{name: "query", description: "Conditions joined by and and or, e.g. type=fire and speed>90", optional: true, variadic: true},
+			},
+			flags: []commandFlag{
+				{name: "region", value: "REGION", description: "Show progress through a region's Pokedex, e.g. kanto"},
+				{name: "generation", value: "N", description: "Show progress through the species of a generation, e.g. 1"},
+				{name: "sort", value: "KEY", description: "Sort caught Pokemon by id, name, caught-at or total-stats"},
+				{name: "reverse", description: "Reverse the sort order"},
+				{name: "type", value: "TYPE", description: "Only list caught Pokemon of a type"},
+				{name: "gen", value: "N", description: "Only list caught Pokemon introduced in a generation"},
+				{name: "stat", value: "EXPR", description: "Only list caught Pokemon with a base stat threshold, e.g. speed>90"},
+				{name: "nickname", value: "NAME", description: "Only list the caught Pokemon with a nickname"},
+				{name: "caught-in", value: "AREA", description: "Only list caught Pokemon caught in a location area"},
+			},
+			examples: []string{
+				"pokedex",
+				"pokedex --sort total-stats",
+				"pokedex --type fire --stat speed>90",
+				"pokedex where type=fire and speed>90",
+				"pokedex --region kanto",
+				"pokedex --generation 2",
+			},
+			callback: commandPokedex,
+		},
+		"history": {
+			name:        "history",
+			description: "Lists past commands, re-run one with !N",
+			category:    categorySystem,
+			examples:    []string{"history", "!12", "!!"},
+			callback:    commandHistory,
+		},
+		"alias": {
+			name:        "alias",
+			description: "Lists, defines or removes command aliases and macros",
+			category:    categorySystem,
+			args: []commandArg{
+				{name: "name", description: "Name of the alias to define or remove", optional: true},
+				{name: "=", description: "Optional separator between the name and the commands", optional: true},
+				{name: "commands", description: "Commands to run, separated by ; and using $1 to $9 or $@ for arguments", optional: true},
+			},
+			flags: []commandFlag{
+				{name: "remove", description: "Remove the named alias"},
+			},
+			examples: []string{"alias", `alias hunt = "map; explore $1; catch $2"`, "alias --remove hunt"},
+			callback: commandAlias,
+		},
+		"config": {
+			name:        "config",
+			description: "Shows the effective settings and where each came from",
+			category:    categorySystem,
+			args: []commandArg{
+				{name: "setting", description: "Setting to show; shows all settings when omitted", optional: true},
+			},
+			examples: []string{"config", "config cache_interval"},
+			callback: commandConfig,
+		},
+		"search": {
+			name:        "search",
+			description: "Search Pokemon and location area names",
+			category:    categoryNavigation,
+			args: []commandArg{
+				{name: "substring", description: "Text the names must contain", identifier: true},
+			},
+			examples: []string{"search chu"},
+			callback: commandSearch,
 		},
 	}
 }
 
-func commandExit(cfg *config, args ...string) error {
-	fmt.Println("Closing the Pokedex... Goodbye!")
+func commandExit(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	fmt.Fprintln(cfg.outWriter(), "Closing the Pokedex... Goodbye!")
 	os.Exit(0)
-	return nil
+	return nil, nil
 }
 
-func commandHelp(cfg *config, args ...string) error {
-	fmt.Println("Welcome to the Pokedex!")
-	fmt.Println("Usage:")
-	fmt.Println()
-
-	commands := getCommands()
-	for _, cmd := range commands {
-		fmt.Printf("%s: %s\n", cmd.name, cmd.description)
+func commandMap(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	pageURL := cfg.nextLocationURL
+	if len(flags) > 0 {
+		var err error
+		pageURL, err = mapPageURL(cfg, flags)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if pageURL == "" {
+		return messageResult{Message: "you're on the last page"}, nil
 	}
-	fmt.Println()
-
-	return nil
-}
 
-func commandMap(cfg *config, args ...string) error {
-	locationAreas, err := getLocationAreas(cfg, cfg.nextLocationURL)
+	locationAreas, err := getLocationAreas(cfg, pageURL)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Update config with new URLs
 	cfg.nextLocationURL = locationAreas.Next
 	cfg.previousLocationURL = locationAreas.Previous
 
-	// Print all location area names
-	for _, area := range locationAreas.Results {
-		fmt.Println(area.Name)
-	}
-
-	return nil
+	return newLocationAreasPage(pageURL, locationAreas), nil
 }
 
-func commandMapb(cfg *config, args ...string) error {
+func commandMapb(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
 	if cfg.previousLocationURL == nil {
-		fmt.Println("you're on the first page")
-		return nil
+		return messageResult{Message: "you're on the first page"}, nil
 	}
 
-	locationAreas, err := getLocationAreas(cfg, *cfg.previousLocationURL)
+	pageURL := *cfg.previousLocationURL
+	locationAreas, err := getLocationAreas(cfg, pageURL)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Update config with new URLs
 	cfg.nextLocationURL = locationAreas.Next
 	cfg.previousLocationURL = locationAreas.Previous
 
-	// Print all location area names
+	return newLocationAreasPage(pageURL, locationAreas), nil
+}
+
+// mapPageURL builds the location area page URL requested by the --page,
+// --limit and --offset flags. Values that are not given are taken from the
+// page map would otherwise show next.
+func mapPageURL(cfg *config, flags commandFlags) (string, error) {
+	offset, limit := pageBounds(cfg.nextLocationURL)
+	offset, limit, err := applyPageFlags(flags, offset, limit)
+	if err != nil {
+		return "", err
+	}
+	return locationAreasPageURL(cfg, offset, limit), nil
+}
+
+// applyPageFlags changes the offset and limit of a page as the --page,
+// --limit and --offset flags ask.
+func applyPageFlags(flags commandFlags, offset, limit int) (int, int, error) {
+	if flags.has("limit") {
+		n, err := flags.int("limit")
+		if err != nil {
+			return 0, 0, err
+		}
+		if n < 1 {
+			return 0, 0, fmt.Errorf("--limit must be at least 1")
+		}
+		limit = n
+	}
+
+	if flags.has("offset") {
+		if flags.has("page") {
+			return 0, 0, fmt.Errorf("--page and --offset cannot be used together")
+		}
+		n, err := flags.int("offset")
+		if err != nil {
+			return 0, 0, err
+		}
+		if n < 0 {
+			return 0, 0, fmt.Errorf("--offset must not be negative")
+		}
+		offset = n
+	}
+
+	if flags.has("page") {
+		n, err := flags.int("page")
+		if err != nil {
+			return 0, 0, err
+		}
+		if n < 1 {
+			return 0, 0, fmt.Errorf("--page must be at least 1")
+		}
+		offset = (n - 1) * limit
+	}
+
+	return offset, limit, nil
+}
+
+type locationAreasPage struct {
+	Page       int      `json:"page"`
+	TotalPages int      `json:"total_pages"`
+	Count      int      `json:"count"`
+	Areas      []string `json:"areas"`
+}
+
+func newLocationAreasPage(pageURL string, locationAreas locationAreasResp) locationAreasPage {
+	offset, limit := pageBounds(pageURL)
+	page := locationAreasPage{
+		Page:       offset/limit + 1,
+		TotalPages: max(1, (locationAreas.Count+limit-1)/limit),
+		Count:      locationAreas.Count,
+		Areas:      []string{},
+	}
 	for _, area := range locationAreas.Results {
-		fmt.Println(area.Name)
+		page.Areas = append(page.Areas, area.Name)
 	}
+	return page
+}
 
-	return nil
+func (r locationAreasPage) writeText(w io.Writer) {
+	// Print all location area names
+	for _, area := range r.Areas {
+		fmt.Fprintln(w, area)
+	}
+	fmt.Fprintf(w, "page %d of %d\n", r.Page, r.TotalPages)
 }
 
-func commandExplore(cfg *config, args ...string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("you must provide a location area name")
+func (r locationAreasPage) tableRows() ([]string, [][]string) {
+	return []string{"area"}, nameRows(r.Areas)
+}
+
+type exploreResult struct {
+	Area    string   `json:"area"`
+	Pokemon []string `json:"pokemon"`
+}
+
+func (r exploreResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Exploring %s...\n", r.Area)
+	fmt.Fprintln(w, "Found Pokemon:")
+	for _, name := range r.Pokemon {
+		fmt.Fprintf(w, " - %s\n", name)
 	}
+}
+
+func (r exploreResult) tableRows() ([]string, [][]string) {
+	return []string{"pokemon"}, nameRows(r.Pokemon)
+}
 
-	locationAreaName := args[0]
-	fmt.Printf("Exploring %s...\n", locationAreaName)
+func commandExplore(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	locationAreaName := cfg.currentArea
+	if len(args) > 0 {
+		locationAreaName = args[0]
+	}
+	if locationAreaName == "" {
+		return nil, fmt.Errorf("you must provide a location area name")
+	}
 
 	locationArea, err := getLocationArea(cfg, locationAreaName)
+	if errors.Is(err, errNotFound) {
+		return nil, notFoundError(cfg, "location-area", locationAreaName)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	fmt.Println("Found Pokemon:")
+	// Exploring an area moves there, so the location and region follow it
+	if name := locationArea.Location.Name; name != "" && name != cfg.currentLocation {
+		location, err := getLocation(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		cfg.currentRegion = location.Region.Name
+		cfg.currentLocation = location.Name
+	}
+	cfg.currentArea = locationArea.Name
+
+	result := exploreResult{Area: locationArea.Name, Pokemon: []string{}}
 	for _, enc := range locationArea.PokemonEncounters {
-		fmt.Printf(" - %s\n", enc.Pokemon.Name)
+		result.Pokemon = append(result.Pokemon, enc.Pokemon.Name)
+		cfg.markSeen(enc.Pokemon.Name)
 	}
 
-	return nil
+	return result, nil
+}
+
+// ownedPokemon is a Pokemon the trainer has caught.
+type ownedPokemon struct {
+	Pokemon
+	Nickname   string
+	Level      int
+	Friendship int
+	HeldItem   string
+	CaughtAt   time.Time
+	CaughtArea string
+	// History records what happened to the Pokemon since it was caught,
+	// such as its evolutions, oldest first.
+	History []string
+}
+
+// Caught Pokemon start at catchLevel with baseFriendship, the base happiness
+// of most species, and gain friendship as they level up.
+const (
+	catchLevel     = 5
+	baseFriendship = 70
+	maxLevel       = 100
+	maxFriendship  = 255
+)
+
+// displayName is the nickname if the Pokemon has one and its name otherwise.
+func (p ownedPokemon) displayName() string {
+	if p.Nickname != "" {
+		return p.Nickname
+	}
+	return p.Name
+}
+
+type catchResult struct {
+	Pokemon  string `json:"pokemon"`
+	Nickname string `json:"nickname,omitempty"`
+	Caught   bool   `json:"caught"`
+}
+
+func (r catchResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Throwing a Pokeball at %s...\n", r.Pokemon)
+	if r.Caught && r.Nickname != "" {
+		fmt.Fprintf(w, "%s was caught and named %s!\n", r.Pokemon, r.Nickname)
+		fmt.Fprintf(w, "You may now inspect it with the inspect command.\n")
+	} else if r.Caught {
+		fmt.Fprintf(w, "%s was caught!\n", r.Pokemon)
+		fmt.Fprintf(w, "You may now inspect it with the inspect command.\n")
+	} else {
+		fmt.Fprintf(w, "%s escaped!\n", r.Pokemon)
+	}
 }
 
-func commandCatch(cfg *config, args ...string) error {
+func commandCatch(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
 	if len(args) == 0 {
-		return fmt.Errorf("you must provide a Pokemon name")
+		return nil, fmt.Errorf("you must provide a Pokemon name")
 	}
 
 	pokemonName := args[0]
-	fmt.Printf("Throwing a Pokeball at %s...\n", pokemonName)
 
 	pokemon, err := getPokemon(cfg, pokemonName)
+	if errors.Is(err, errNotFound) {
+		return nil, notFoundError(cfg, "pokemon", pokemonName)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Use base experience to determine catch difficulty
 	// Higher base experience = harder to catch
-	const maxCatchChance = 50 // Base 50% chance for Pokemon with 0 base experience
-	catchChance := maxCatchChance
+	s := cfg.currentSettings()
+	catchChance := s.CatchBaseChance
 	if pokemon.BaseExperience > 0 {
-		// Reduce catch chance based on base experience, minimum 5% chance
-		catchChance = max(5, maxCatchChance-pokemon.BaseExperience/10)
+		// Reduce catch chance based on base experience, down to the minimum
+		catchChance = max(s.CatchMinChance, s.CatchBaseChance-pokemon.BaseExperience/s.CatchExpDivisor)
 	}
 
 	// Generate random number between 1-100
-	if rand.Intn(100)+1 <= catchChance {
-		cfg.caughtPokemon[pokemon.Name] = pokemon
-		fmt.Printf("%s was caught!\n", pokemon.Name)
-		fmt.Printf("You may now inspect it with the inspect command.\n")
-	} else {
-		fmt.Printf("%s escaped!\n", pokemon.Name)
+	cfg.ballsThrown++
+	result := catchResult{Pokemon: pokemon.Name}
+	if cfg.roll() <= catchChance {
+		cfg.caughtPokemon[pokemon.Name] = ownedPokemon{
+			Pokemon:    pokemon,
+			Nickname:   flags.get("nickname"),
+			Level:      catchLevel,
+			Friendship: baseFriendship,
+			CaughtAt:   cfg.currentTime(),
+			CaughtArea: cfg.currentArea,
+		}
+		result.Nickname = flags.get("nickname")
+		result.Caught = true
 	}
+	cfg.markSeen(pokemon.Name)
 
-	return nil
+	return result, nil
 }
 
-func commandInspect(cfg *config, args ...string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("you must provide a Pokemon name")
-	}
+type inspectResult struct {
+	Name       string         `json:"name"`
+	Nickname   string         `json:"nickname,omitempty"`
+	Height     int            `json:"height"`
+	Weight     int            `json:"weight"`
+	Level      int            `json:"level"`
+	Friendship int            `json:"friendship"`
+	HeldItem   string         `json:"held_item,omitempty"`
+	History    []string       `json:"history,omitempty"`
+	Stats      []statValue    `json:"stats"`
+	Types      []string       `json:"types"`
+	SpriteURL  string         `json:"sprite_url,omitempty"`
+	Species    *speciesResult `json:"species,omitempty"`
+	// sprite is the rendered sprite shown above the text output.
+	sprite string
+}
 
-	pokemonName := args[0]
+type statValue struct {
+	Name     string `json:"name"`
+	BaseStat int    `json:"base_stat"`
+}
 
-	// Check if the Pokemon has been caught
-	pokemon, exists := cfg.caughtPokemon[pokemonName]
-	if !exists {
-		fmt.Println("you have not caught that pokemon")
-		return nil
+func newInspectResult(pokemon ownedPokemon) inspectResult {
+	result := inspectResult{
+		Name:       pokemon.Name,
+		Nickname:   pokemon.Nickname,
+		Height:     pokemon.Height,
+		Weight:     pokemon.Weight,
+		Level:      pokemon.Level,
+		Friendship: pokemon.Friendship,
+		HeldItem:   pokemon.HeldItem,
+		History:    pokemon.History,
+		Stats:      []statValue{},
+		Types:      []string{},
 	}
-
-	// Display Pokemon information
-	fmt.Printf("Name: %s\n", pokemon.Name)
-	fmt.Printf("Height: %d\n", pokemon.Height)
-	fmt.Printf("Weight: %d\n", pokemon.Weight)
-
-	fmt.Println("Stats:")
 	for _, stat := range pokemon.Stats {
-		fmt.Printf("  -%s: %d\n", stat.Stat.Name, stat.BaseStat)
+		result.Stats = append(result.Stats, statValue{Name: stat.Stat.Name, BaseStat: stat.BaseStat})
 	}
-
-	fmt.Println("Types:")
 	for _, typeInfo := range pokemon.Types {
-		fmt.Printf("  - %s\n", typeInfo.Type.Name)
+		result.Types = append(result.Types, typeInfo.Type.Name)
 	}
-
-	return nil
+	return result
 }
 
-func commandPokedex(cfg *config, args ...string) error {
-	fmt.Println("Your Pokedex:")
+func (r inspectResult) writeText(w io.Writer) {
+	fmt.Fprint(w, r.sprite)
 
-	if len(cfg.caughtPokemon) == 0 {
-		fmt.Println(" (No Pokemon caught yet)")
-		return nil
+	// Display Pokemon information
+	fmt.Fprintf(w, "Name: %s\n", r.Name)
+	if r.Nickname != "" {
+		fmt.Fprintf(w, "Nickname: %s\n", r.Nickname)
 	}
+	fmt.Fprintf(w, "Height: %d\n", r.Height)
+	fmt.Fprintf(w, "Weight: %d\n", r.Weight)
+	r.writeTraining(w)
 
-	for pokemonName := range cfg.caughtPokemon {
-		fmt.Printf(" - %s\n", pokemonName)
+	fmt.Fprintln(w, "Stats:")
+	for _, stat := range r.Stats {
+		fmt.Fprintf(w, "  -%s: %d\n", stat.Name, stat.BaseStat)
 	}
 
-	return nil
-}
+	fmt.Fprintln(w, "Types:")
+	for _, typeName := range r.Types {
+		fmt.Fprintf(w, "  - %s\n", typeName)
+	}
 
-type locationAreasResp struct {
-	Count    int     `json:"count"`
-	Next     string  `json:"next"`
-	Previous *string `json:"previous"`
-	Results  []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"results"`
+	r.writeHistory(w)
+	r.writeSpecies(w)
 }
 
-type locationAreaResp struct {
-	ID                int    `json:"id"`
-	Name              string `json:"name"`
-	GameIndex         int    `json:"game_index"`
-	PokemonEncounters []struct {
-		Pokemon struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"pokemon"`
-	} `json:"pokemon_encounters"`
+// writeTraining writes the state that changes as the Pokemon is trained.
+func (r inspectResult) writeTraining(w io.Writer) {
+	fmt.Fprintf(w, "Level: %d\n", r.Level)
+	fmt.Fprintf(w, "Friendship: %d\n", r.Friendship)
+	if r.HeldItem != "" {
+		fmt.Fprintf(w, "Held item: %s\n", r.HeldItem)
+	}
 }
 
-type Pokemon struct {
-	ID             int    `json:"id"`
-	Name           string `json:"name"`
-	BaseExperience int    `json:"base_experience"`
-	Height         int    `json:"height"`
-	Weight         int    `json:"weight"`
-	Stats          []struct {
-		BaseStat int `json:"base_stat"`
-		Stat     struct {
-			Name string `json:"name"`
-		} `json:"stat"`
-	} `json:"stats"`
-	Types []struct {
-		Type struct {
-			Name string `json:"name"`
-		} `json:"type"`
-	} `json:"types"`
+func (r inspectResult) writeHistory(w io.Writer) {
+	if len(r.History) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "History:")
+	for _, event := range r.History {
+		fmt.Fprintf(w, "  - %s\n", event)
+	}
 }
 
-func getLocationAreas(cfg *config, pageURL string) (locationAreasResp, error) {
-	// Check if we have the data in cache
-	if val, ok := cfg.pokeapiClient.Get(pageURL); ok {
-		fmt.Printf("Using cached data for %s\n", pageURL)
-		locationAreasResponse := locationAreasResp{}
-		err := json.Unmarshal(val, &locationAreasResponse)
-		if err != nil {
-			return locationAreasResp{}, err
-		}
-		return locationAreasResponse, nil
+func (r inspectResult) writeSpecies(w io.Writer) {
+	if r.Species == nil {
+		return
 	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Species: %s\n", r.Species.Genus)
+	r.Species.writeDetails(w)
+}
 
-	fmt.Printf("Making HTTP request to %s\n", pageURL)
-	res, err := http.Get(pageURL)
-	if err != nil {
-		return locationAreasResp{}, err
+func (r inspectResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, stat := range r.Stats {
+		rows = append(rows, []string{stat.Name, strconv.Itoa(stat.BaseStat)})
 	}
-	defer res.Body.Close()
+	return []string{"stat", "base"}, rows
+}
 
-	dat, err := io.ReadAll(res.Body)
-	if err != nil {
-		return locationAreasResp{}, err
+// markSeen records that the trainer has seen the named Pokemon.
+func (cfg *config) markSeen(name string) {
+	if cfg.seenPokemon == nil {
+		cfg.seenPokemon = map[string]bool{}
 	}
+	cfg.seenPokemon[name] = true
+}
 
-	locationAreasResponse := locationAreasResp{}
-	err = json.Unmarshal(dat, &locationAreasResponse)
-	if err != nil {
-		return locationAreasResp{}, err
+// currentTime returns the time now, or the time set by tests.
+func (cfg *config) currentTime() time.Time {
+	if cfg.now != nil {
+		return cfg.now()
 	}
-
-	// Add to cache
-	cfg.pokeapiClient.Add(pageURL, dat)
-
-	return locationAreasResponse, nil
+	return time.Now()
 }
 
-func getLocationArea(cfg *config, locationAreaName string) (locationAreaResp, error) {
-	url := "https://pokeapi.co/api/v2/location-area/" + locationAreaName
-
-	// Check if we have the data in cache
-	if val, ok := cfg.pokeapiClient.Get(url); ok {
-		fmt.Printf("Using cached data for %s\n", url)
-		locationAreaResponse := locationAreaResp{}
-		err := json.Unmarshal(val, &locationAreaResponse)
-		if err != nil {
-			return locationAreaResp{}, err
-		}
-		return locationAreaResponse, nil
+// roll returns a random number between 1 and 100.
+func (cfg *config) roll() int {
+	if cfg.rollPercent != nil {
+		return cfg.rollPercent()
 	}
+	return rand.Intn(100) + 1
+}
 
-	fmt.Printf("Making HTTP request to %s\n", url)
-	res, err := http.Get(url)
-	if err != nil {
-		return locationAreaResp{}, err
+func commandInspect(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide a Pokemon name")
 	}
-	defer res.Body.Close()
 
-	dat, err := io.ReadAll(res.Body)
-	if err != nil {
-		return locationAreaResp{}, err
+	pokemonName := args[0]
+
+	// Check if the Pokemon has been caught
+	pokemon, exists := findCaughtPokemon(cfg, pokemonName)
+	if !exists && cfg.seenPokemon[apiName(pokemonName)] {
+		return inspectSeen(cfg, apiName(pokemonName))
 	}
+	if !exists {
+		message := "you have not caught that pokemon"
 
-	locationAreaResponse := locationAreaResp{}
-	err = json.Unmarshal(dat, &locationAreaResponse)
-	if err != nil {
-		return locationAreaResp{}, err
+		caughtNames := make([]string, 0, len(cfg.caughtPokemon))
+		for name, caught := range cfg.caughtPokemon {
+			caughtNames = append(caughtNames, name)
+			if caught.Nickname != "" {
+				caughtNames = append(caughtNames, caught.Nickname)
+			}
+		}
+		if suggestions := fuzzy.Closest(pokemonName, caughtNames, maxSuggestions); len(suggestions) > 0 {
+			message += fmt.Sprintf("\ndid you mean %s?", strings.Join(suggestions, ", "))
+		}
+		return messageResult{Message: message}, nil
 	}
 
-	// Add to cache
-	cfg.pokeapiClient.Add(url, dat)
+	result := newInspectResult(pokemon)
+	if flags.has("sprite") {
+		sprite, err := renderSprite(cfg, pokemon.Pokemon)
+		if err != nil {
+			return nil, err
+		}
+		result.SpriteURL = pokemon.Sprites.FrontDefault
+		result.sprite = sprite
+	}
+	if flags.has("full") {
+		speciesName := pokemon.Species.Name
+		if speciesName == "" {
+			speciesName = pokemon.Name
+		}
+		species, err := getSpeciesOf(cfg, speciesName)
+		if err != nil {
+			return nil, err
+		}
+		s := cfg.currentSettings()
+		details := newSpeciesResult(species, s.Language, s.GameVersion)
+		result.Species = &details
+	}
 
-	return locationAreaResponse, nil
+	return result, nil
 }
 
-func getPokemon(cfg *config, pokemonName string) (Pokemon, error) {
-	url := "https://pokeapi.co/api/v2/pokemon/" + pokemonName
+// seenResult is what inspect shows of a Pokemon that has been seen but not
+// caught: like a Pokedex entry in the games, only its name and types.
+type seenResult struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"`
+	// Caught is always false, to tell the result apart from a caught one.
+	Caught bool `json:"caught"`
+}
 
-	// Check if we have the data in cache
-	if val, ok := cfg.pokeapiClient.Get(url); ok {
-		fmt.Printf("Using cached data for %s\n", url)
-		pokemonResponse := Pokemon{}
-		err := json.Unmarshal(val, &pokemonResponse)
-		if err != nil {
-			return Pokemon{}, err
-		}
-		return pokemonResponse, nil
+func (r seenResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Name: %s\n", r.Name)
+	fmt.Fprintln(w, "Types:")
+	for _, typeName := range r.Types {
+		fmt.Fprintf(w, "  - %s\n", typeName)
 	}
+	fmt.Fprintf(w, "You have seen %s but not caught it.\n", r.Name)
+}
 
-	fmt.Printf("Making HTTP request to %s\n", url)
-	res, err := http.Get(url)
+func inspectSeen(cfg *config, name string) (commandResult, error) {
+	pokemon, err := getPokemon(cfg, name)
 	if err != nil {
-		return Pokemon{}, err
+		return nil, err
 	}
-	defer res.Body.Close()
 
-	dat, err := io.ReadAll(res.Body)
-	if err != nil {
-		return Pokemon{}, err
+	result := seenResult{Name: pokemon.Name, Types: []string{}}
+	for _, typeInfo := range pokemon.Types {
+		result.Types = append(result.Types, typeInfo.Type.Name)
 	}
+	return result, nil
+}
 
-	pokemonResponse := Pokemon{}
-	err = json.Unmarshal(dat, &pokemonResponse)
-	if err != nil {
-		return Pokemon{}, err
+// findCaughtPokemon looks a caught Pokemon up by its nickname or its name.
+// Nicknames keep the case they were given but match in any case.
+func findCaughtPokemon(cfg *config, name string) (ownedPokemon, bool) {
+	for _, caught := range cfg.caughtPokemon {
+		if caught.Nickname != "" && strings.EqualFold(caught.Nickname, name) {
+			return caught, true
+		}
 	}
-
-	// Add to cache
-	cfg.pokeapiClient.Add(url, dat)
-
-	return pokemonResponse, nil
+	caught, ok := cfg.caughtPokemon[apiName(name)]
+	return caught, ok
 }
 
-func cleanInput(text string) []string {
-	// Trim leading and trailing whitespace and convert to lowercase
-	cleaned := strings.ToLower(strings.TrimSpace(text))
-
-	// If the cleaned string is empty, return empty slice
-	if cleaned == "" {
-		return []string{}
+// findPokemon finds a caught Pokemon by nickname or name, and any other
+// Pokemon through PokeAPI.
+func findPokemon(cfg *config, name string) (ownedPokemon, error) {
+	if caught, ok := findCaughtPokemon(cfg, name); ok {
+		return caught, nil
 	}
 
-	// Split by whitespace
-	words := strings.Fields(cleaned)
-
-	return words
+	pokemon, err := getPokemon(cfg, apiName(name))
+	if errors.Is(err, errNotFound) {
+		return ownedPokemon{}, notFoundError(cfg, "pokemon", name)
+	}
+	if err != nil {
+		return ownedPokemon{}, err
+	}
+	return ownedPokemon{Pokemon: pokemon}, nil
 }
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type movesResult struct {
+	Pokemon      string        `json:"pokemon"`
+	VersionGroup string        `json:"version_group"`
+	Moves        []learnedMove `json:"moves"`
+}
+
+type learnedMove struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	// Level is the level a level-up move is learned at, 0 for other methods
+	// and for moves known from the start.
+	Level int `json:"level,omitempty"`
+}
+
+func (r movesResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s moves in %s:\n", r.Pokemon, r.VersionGroup)
+	if len(r.Moves) == 0 {
+		fmt.Fprintln(w, " (No moves)")
+		return
+	}
+
+	method := ""
+	for _, move := range r.Moves {
+		if move.Method != method {
+			method = move.Method
+			fmt.Fprintf(w, "%s:\n", method)
+		}
+		if method == "level-up" {
+			fmt.Fprintf(w, "  %3d %s\n", move.Level, move.Name)
+		} else {
+			fmt.Fprintf(w, "  %s\n", move.Name)
+		}
+	}
+}
+
+func (r movesResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, move := range r.Moves {
+		level := ""
+		if move.Method == "level-up" {
+			level = strconv.Itoa(move.Level)
+		}
+		rows = append(rows, []string{move.Method, level, move.Name})
+	}
+	return []string{"method", "level", "move"}, rows
+}
+
+func commandMoves(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide a Pokemon name")
+	}
+
+	pokemon, err := findPokemon(cfg, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	versionGroup := apiName(flags.get("version-group"))
+	if versionGroup == "" {
+		versionGroup = latestVersionGroup(pokemon.Pokemon)
+	}
+	method := apiName(flags.get("method"))
+
+	result := movesResult{Pokemon: pokemon.displayName(), VersionGroup: versionGroup, Moves: []learnedMove{}}
+	for _, move := range pokemon.Moves {
+		for _, detail := range move.VersionGroupDetails {
+			if detail.VersionGroup.Name != versionGroup {
+				continue
+			}
+			if method != "" && detail.MoveLearnMethod.Name != method {
+				continue
+			}
+			learned := learnedMove{Name: move.Move.Name, Method: detail.MoveLearnMethod.Name}
+			if learned.Method == "level-up" {
+				learned.Level = detail.LevelLearnedAt
+			}
+			result.Moves = append(result.Moves, learned)
+		}
+	}
+	sortLearnedMoves(result.Moves)
+
+	return result, nil
+}
+
+// sortLearnedMoves puts level-up moves first, by level, followed by the other
+// methods in alphabetical order; moves of a method are sorted by name.
+func sortLearnedMoves(moves []learnedMove) {
+	sort.SliceStable(moves, func(i, j int) bool {
+		a, b := moves[i], moves[j]
+		if a.Method != b.Method {
+			if a.Method == "level-up" || b.Method == "level-up" {
+				return a.Method == "level-up"
+			}
+			return a.Method < b.Method
+		}
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		return a.Name < b.Name
+	})
+}
+
+// latestVersionGroup returns the most recent version group pokemon learns
+// moves in. PokeAPI numbers version groups in release order, so the newest is
+// the one with the highest id in its URL.
+func latestVersionGroup(pokemon Pokemon) string {
+	latest, latestID := "", -1
+	for _, move := range pokemon.Moves {
+		for _, detail := range move.VersionGroupDetails {
+			id, err := strconv.Atoi(path.Base(strings.TrimSuffix(detail.VersionGroup.URL, "/")))
+			if err != nil {
+				id = 0
+			}
+			if id > latestID {
+				latest, latestID = detail.VersionGroup.Name, id
+			}
+		}
+	}
+	return latest
+}
+
+type moveResult struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	DamageClass string `json:"damage_class"`
+	// Power, Accuracy and PP are nil for moves without them, such as status
+	// moves that never miss.
+	Power    *int   `json:"power"`
+	Accuracy *int   `json:"accuracy"`
+	PP       *int   `json:"pp"`
+	Priority int    `json:"priority"`
+	Effect   string `json:"effect,omitempty"`
+}
+
+func (r moveResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, r.Name)
+	fmt.Fprintf(w, "Type: %s\n", r.Type)
+	fmt.Fprintf(w, "Damage class: %s\n", r.DamageClass)
+	fmt.Fprintf(w, "Power: %s\n", optionalNumber(r.Power))
+	fmt.Fprintf(w, "Accuracy: %s\n", optionalNumber(r.Accuracy))
+	fmt.Fprintf(w, "PP: %s\n", optionalNumber(r.PP))
+	if r.Priority != 0 {
+		fmt.Fprintf(w, "Priority: %+d\n", r.Priority)
+	}
+	if r.Effect != "" {
+		fmt.Fprintf(w, "Effect: %s\n", r.Effect)
+	}
+}
+
+// optionalNumber writes n, or a dash when PokeAPI has no value.
+func optionalNumber(n *int) string {
+	if n == nil {
+		return "-"
+	}
+	return strconv.Itoa(*n)
+}
+
+func commandMove(cfg *config, _ commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide a move name")
+	}
+
+	move, err := getMove(cfg, args[0])
+	if errors.Is(err, errNotFound) {
+		return nil, notFoundError(cfg, "move", args[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	effect := selectEffect(move.EffectEntries, cfg.currentSettings().Language)
+	if move.EffectChance != nil {
+		effect = strings.ReplaceAll(effect, "$effect_chance", strconv.Itoa(*move.EffectChance))
+	}
+
+	return moveResult{
+		Name:        move.Name,
+		Type:        move.Type.Name,
+		DamageClass: move.DamageClass.Name,
+		Power:       move.Power,
+		Accuracy:    move.Accuracy,
+		PP:          move.PP,
+		Priority:    move.Priority,
+		Effect:      effect,
+	}, nil
+}
+
+// selectEffect returns the short effect text in language, or in English when
+// there is none in language.
+func selectEffect(entries []effectEntry, language string) string {
+	fallback := ""
+	for _, entry := range entries {
+		switch entry.Language.Name {
+		case language:
+			return cleanFlavorText(entry.ShortEffect)
+		case "en":
+			fallback = cleanFlavorText(entry.ShortEffect)
+		}
+	}
+	return fallback
+}
+
+type abilityResult struct {
+	Name       string   `json:"name"`
+	Generation string   `json:"generation"`
+	Effect     string   `json:"effect,omitempty"`
+	Pokemon    []string `json:"pokemon"`
+	// Hidden lists the Pokemon that only have the ability as a hidden one.
+	Hidden []string `json:"hidden"`
+}
+
+func (r abilityResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, r.Name)
+	fmt.Fprintf(w, "Generation: %s\n", r.Generation)
+	if r.Effect != "" {
+		fmt.Fprintf(w, "Effect: %s\n", r.Effect)
+	}
+	if len(r.Pokemon) > 0 {
+		fmt.Fprintf(w, "Pokemon: %s\n", strings.Join(r.Pokemon, ", "))
+	}
+	if len(r.Hidden) > 0 {
+		fmt.Fprintf(w, "Hidden ability of: %s\n", strings.Join(r.Hidden, ", "))
+	}
+}
+
+func commandAbility(cfg *config, _ commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide an ability name")
+	}
+
+	ability, err := getAbility(cfg, args[0])
+	if errors.Is(err, errNotFound) {
+		return nil, notFoundError(cfg, "ability", args[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := abilityResult{
+		Name:       ability.Name,
+		Generation: ability.Generation.Name,
+		Effect:     selectEffect(ability.EffectEntries, cfg.currentSettings().Language),
+		Pokemon:    []string{},
+		Hidden:     []string{},
+	}
+	for _, p := range ability.Pokemon {
+		if p.IsHidden {
+			result.Hidden = append(result.Hidden, p.Pokemon.Name)
+		} else {
+			result.Pokemon = append(result.Pokemon, p.Pokemon.Name)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+type regionsResult struct {
+	Regions []string `json:"regions"`
+}
+
+func (r regionsResult) writeText(w io.Writer) {
+	for _, region := range r.Regions {
+		fmt.Fprintln(w, region)
+	}
+}
+
+func (r regionsResult) tableRows() ([]string, [][]string) {
+	return []string{"region"}, nameRows(r.Regions)
+}
+
+func commandRegions(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	regions, err := getRegions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := regionsResult{Regions: []string{}}
+	for _, region := range regions.Results {
+		result.Regions = append(result.Regions, region.Name)
+	}
+
+	return result, nil
+}
+
+type regionLocationsResult struct {
+	Region    string   `json:"region"`
+	Locations []string `json:"locations"`
+}
+
+func newRegionLocationsResult(region regionResp) regionLocationsResult {
+	result := regionLocationsResult{Region: region.Name, Locations: []string{}}
+	for _, location := range region.Locations {
+		result.Locations = append(result.Locations, location.Name)
+	}
+	return result
+}
+
+func (r regionLocationsResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Locations in %s:\n", r.Region)
+	for _, location := range r.Locations {
+		fmt.Fprintf(w, " - %s\n", location)
+	}
+}
+
+func (r regionLocationsResult) tableRows() ([]string, [][]string) {
+	return []string{"location"}, nameRows(r.Locations)
+}
+
+func commandRegion(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide a region name")
+	}
+
+	region, err := getRegion(cfg, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// Selecting a new region resets the narrower selections
+	if cfg.currentRegion != region.Name {
+		cfg.currentLocation = ""
+		cfg.currentArea = ""
+	}
+	cfg.currentRegion = region.Name
+
+	return newRegionLocationsResult(region), nil
+}
+
+func commandLocations(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	if cfg.currentRegion == "" {
+		return nil, fmt.Errorf("no region selected, use the region command first")
+	}
+
+	region, err := getRegion(cfg, cfg.currentRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRegionLocationsResult(region), nil
+}
+
+type locationAreasResult struct {
+	Location    string   `json:"location"`
+	Areas       []string `json:"areas"`
+	CurrentArea string   `json:"current_area,omitempty"`
+}
+
+func (r locationAreasResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Areas in %s:\n", r.Location)
+	if len(r.Areas) == 0 {
+		fmt.Fprintln(w, " (No areas found)")
+		return
+	}
+	for _, area := range r.Areas {
+		fmt.Fprintf(w, " - %s\n", area)
+	}
+
+	if r.CurrentArea != "" {
+		fmt.Fprintf(w, "Current area set to %s\n", r.CurrentArea)
+	}
+}
+
+func (r locationAreasResult) tableRows() ([]string, [][]string) {
+	return []string{"area"}, nameRows(r.Areas)
+}
+
+func commandAreas(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	locationName := cfg.currentLocation
+	if len(args) > 0 {
+		locationName = args[0]
+	}
+	if locationName == "" {
+		return nil, fmt.Errorf("you must provide a location name")
+	}
+
+	location, err := getLocation(cfg, locationName)
+	if err != nil {
+		return nil, err
+	}
+
+	// An area chosen in another location no longer applies
+	if location.Name != cfg.currentLocation {
+		cfg.currentArea = ""
+	}
+	cfg.currentRegion = location.Region.Name
+	cfg.currentLocation = location.Name
+
+	result := locationAreasResult{Location: location.Name, Areas: []string{}}
+	for _, area := range location.Areas {
+		result.Areas = append(result.Areas, area.Name)
+	}
+
+	// A location with a single area needs no further choice
+	if len(location.Areas) == 1 {
+		cfg.currentArea = location.Areas[0].Name
+		result.CurrentArea = cfg.currentArea
+	}
+
+	return result, nil
+}
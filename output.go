@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/see-why/Pokedex/internal/output"
+)
+
+type outputFormat string
+
+const (
+	outputText  outputFormat = "text"
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+	outputYAML  outputFormat = "yaml"
+)
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch format := outputFormat(s); format {
+	case outputText, outputTable, outputJSON, outputYAML:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown output format %q, expected json, yaml, table or text", s)
+}
+
+// commandResult is what a command produces. The json and yaml formats encode
+// its exported fields; the text format calls writeText.
+type commandResult interface {
+	writeText(w io.Writer)
+}
+
+// tableResult is a commandResult that can be laid out as rows for the table
+// format. Results that are not tabular fall back to their text form.
+type tableResult interface {
+	commandResult
+	tableRows() (header []string, rows [][]string)
+}
+
+// colorResult is a commandResult with a colored text form, used instead of
+// writeText when output goes to a terminal that allows colors.
+type colorResult interface {
+	commandResult
+	writeColorText(w io.Writer)
+}
+
+// messageResult is a result that is only a short message for the user.
+type messageResult struct {
+	Message string `json:"message"`
+}
+
+func (r messageResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, r.Message)
+}
+
+// renderResult writes result to w in the given format. color selects the
+// colored text form of results that have one.
+func renderResult(w io.Writer, format outputFormat, result commandResult, color bool) error {
+	switch format {
+	case outputJSON:
+		return output.WriteJSON(w, result)
+	case outputYAML:
+		return output.WriteYAML(w, result)
+	case outputTable:
+		if table, ok := result.(tableResult); ok {
+			header, rows := table.tableRows()
+			return output.WriteTable(w, header, rows)
+		}
+	}
+
+	if colored, ok := result.(colorResult); ok && color {
+		colored.writeColorText(w)
+		return nil
+	}
+	result.writeText(w)
+	return nil
+}
+
+// outWriter is where command output goes, stdout unless config says otherwise.
+func (cfg *config) outWriter() io.Writer {
+	if cfg.out == nil {
+		return os.Stdout
+	}
+	return cfg.out
+}
+
+// diagWriter is where diagnostics such as cache and request messages go.
+// They are discarded unless config provides a writer for them.
+func (cfg *config) diagWriter() io.Writer {
+	if cfg.diag == nil {
+		return io.Discard
+	}
+	return cfg.diag
+}
+
+// nameRows turns a list of names into single-column table rows.
+func nameRows(names []string) [][]string {
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, []string{name})
+	}
+	return rows
+}
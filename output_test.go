@@ -0,0 +1,707 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/see-why/Pokedex/internal/pokecache"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// testResponses are canned PokeAPI responses, keyed by URL, that are loaded
+// into the cache so the golden tests never reach the network.
+var testResponses = map[string]string{
+	pokeapiBaseURL + "/location-area": `{
+		"count": 45,
+		"next": "https://pokeapi.co/api/v2/location-area?offset=20&limit=20",
+		"previous": null,
+		"results": [
+			{"name": "canalave-city-area", "url": ""},
+			{"name": "eterna-city-area", "url": ""},
+			{"name": "pastoria-city-area", "url": ""}
+		]
+	}`,
+	pokeapiBaseURL + "/region": `{
+		"count": 2,
+		"results": [{"name": "kanto", "url": ""}, {"name": "johto", "url": ""}]
+	}`,
+	pokeapiBaseURL + "/region/kanto": `{
+		"id": 1,
+		"name": "kanto",
+		"locations": [{"name": "pallet-town", "url": ""}, {"name": "viridian-forest", "url": ""}],
+		"pokedexes": [{"name": "kanto", "url": ""}, {"name": "letsgo-kanto", "url": ""}]
+	}`,
+	pokeapiBaseURL + "/pokedex/kanto": `{
+		"id": 2,
+		"name": "kanto",
+		"pokemon_entries": [
+			{"entry_number": 1, "pokemon_species": {"name": "bulbasaur", "url": "https://pokeapi.co/api/v2/pokemon-species/1/"}},
+			{"entry_number": 16, "pokemon_species": {"name": "pidgey", "url": "https://pokeapi.co/api/v2/pokemon-species/16/"}},
+			{"entry_number": 25, "pokemon_species": {"name": "pikachu", "url": "https://pokeapi.co/api/v2/pokemon-species/25/"}},
+			{"entry_number": 26, "pokemon_species": {"name": "raichu", "url": "https://pokeapi.co/api/v2/pokemon-species/26/"}}
+		]
+	}`,
+	pokeapiBaseURL + "/generation/generation-ii": `{
+		"id": 2,
+		"name": "generation-ii",
+		"main_region": {"name": "johto"},
+		"pokemon_species": [
+			{"name": "togepi", "url": "https://pokeapi.co/api/v2/pokemon-species/175/"},
+			{"name": "chikorita", "url": "https://pokeapi.co/api/v2/pokemon-species/152/"},
+			{"name": "pichu", "url": "https://pokeapi.co/api/v2/pokemon-species/172/"}
+		]
+	}`,
+	pokeapiBaseURL + "/location/pallet-town": `{
+		"id": 1,
+		"name": "pallet-town",
+		"region": {"name": "kanto", "url": ""},
+		"areas": [{"name": "pallet-town-area", "url": ""}]
+	}`,
+	pokeapiBaseURL + "/location-area/pallet-town-area": `{
+		"id": 1,
+		"name": "pallet-town-area",
+		"location": {"name": "pallet-town", "url": ""},
+		"pokemon_encounters": [
+			{"pokemon": {"name": "pidgey", "url": ""}},
+			{"pokemon": {"name": "rattata", "url": ""}}
+		]
+	}`,
+	pokeapiBaseURL + "/pokemon/pikachu": `{
+		"id": 25,
+		"name": "pikachu",
+		"base_experience": 112,
+		"height": 4,
+		"weight": 60,
+		"stats": [
+			{"base_stat": 35, "stat": {"name": "hp"}},
+			{"base_stat": 90, "stat": {"name": "speed"}}
+		],
+		"types": [{"type": {"name": "electric"}}],
+		"sprites": {"front_default": "` + pikachuSpriteURL + `"},
+		"abilities": [
+			{"ability": {"name": "static"}, "is_hidden": false, "slot": 1},
+			{"ability": {"name": "lightning-rod"}, "is_hidden": true, "slot": 3}
+		],
+		"moves": [
+			{"move": {"name": "thunder-shock"}, "version_group_details": [{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "red-blue", "url": "https://pokeapi.co/api/v2/version-group/1/"}}, {"level_learned_at": 1, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "sword-shield", "url": "https://pokeapi.co/api/v2/version-group/20/"}}]},
+			{"move": {"name": "growl"}, "version_group_details": [{"level_learned_at": 1, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "red-blue", "url": "https://pokeapi.co/api/v2/version-group/1/"}}, {"level_learned_at": 1, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "sword-shield", "url": "https://pokeapi.co/api/v2/version-group/20/"}}]},
+			{"move": {"name": "thunder-wave"}, "version_group_details": [{"level_learned_at": 9, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "red-blue", "url": "https://pokeapi.co/api/v2/version-group/1/"}}, {"level_learned_at": 4, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "sword-shield", "url": "https://pokeapi.co/api/v2/version-group/20/"}}]},
+			{"move": {"name": "quick-attack"}, "version_group_details": [{"level_learned_at": 16, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "red-blue", "url": "https://pokeapi.co/api/v2/version-group/1/"}}, {"level_learned_at": 1, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "sword-shield", "url": "https://pokeapi.co/api/v2/version-group/20/"}}]},
+			{"move": {"name": "thunderbolt"}, "version_group_details": [{"level_learned_at": 0, "move_learn_method": {"name": "machine"}, "version_group": {"name": "red-blue", "url": "https://pokeapi.co/api/v2/version-group/1/"}}, {"level_learned_at": 36, "move_learn_method": {"name": "level-up"}, "version_group": {"name": "sword-shield", "url": "https://pokeapi.co/api/v2/version-group/20/"}}, {"level_learned_at": 0, "move_learn_method": {"name": "machine"}, "version_group": {"name": "sword-shield", "url": "https://pokeapi.co/api/v2/version-group/20/"}}]},
+			{"move": {"name": "volt-tackle"}, "version_group_details": [{"level_learned_at": 0, "move_learn_method": {"name": "egg"}, "version_group": {"name": "sword-shield", "url": "https://pokeapi.co/api/v2/version-group/20/"}}]}
+		],
+		"species": {"name": "pikachu", "url": ""}
+	}`,
+	pokeapiBaseURL + "/move/thunderbolt": `{
+		"name": "thunderbolt",
+		"power": 90,
+		"accuracy": 100,
+		"pp": 15,
+		"priority": 0,
+		"effect_chance": 10,
+		"damage_class": {"name": "special"},
+		"type": {"name": "electric"},
+		"effect_entries": [{"short_effect": "Has a $effect_chance% chance to\nparalyze the target.", "language": {"name": "en"}}]
+	}`,
+	pokeapiBaseURL + "/move/quick-attack": `{
+		"name": "quick-attack",
+		"power": 40,
+		"accuracy": 100,
+		"pp": 30,
+		"priority": 1,
+		"effect_chance": null,
+		"damage_class": {"name": "physical"},
+		"type": {"name": "normal"},
+		"effect_entries": [{"short_effect": "Usually goes first.", "language": {"name": "en"}}]
+	}`,
+	pokeapiBaseURL + "/move/growl": `{
+		"name": "growl",
+		"power": null,
+		"accuracy": 100,
+		"pp": 40,
+		"priority": 0,
+		"effect_chance": null,
+		"damage_class": {"name": "status"},
+		"type": {"name": "normal"},
+		"effect_entries": [{"short_effect": "Lowers the target's Attack by one stage.", "language": {"name": "en"}}]
+	}`,
+	pokeapiBaseURL + "/ability/static": `{
+		"name": "static",
+		"generation": {"name": "generation-iii"},
+		"effect_entries": [
+			{"short_effect": "Has a 30% chance of paralyzing attacking Pokémon on contact.", "language": {"name": "en"}},
+			{"short_effect": "Peut paralyser l'attaquant au contact.", "language": {"name": "fr"}}
+		],
+		"pokemon": [
+			{"is_hidden": false, "pokemon": {"name": "pikachu"}},
+			{"is_hidden": false, "pokemon": {"name": "raichu"}},
+			{"is_hidden": true, "pokemon": {"name": "electrike"}}
+		]
+	}`,
+
+	pokeapiBaseURL + "/pokemon/raichu": `{
+		"id": 26,
+		"name": "raichu",
+		"base_experience": 243,
+		"height": 8,
+		"weight": 300,
+		"stats": [
+			{"base_stat": 60, "stat": {"name": "hp"}},
+			{"base_stat": 110, "stat": {"name": "speed"}}
+		],
+		"types": [{"type": {"name": "electric"}}],
+		"species": {"name": "raichu", "url": ""}
+	}`,
+	pokeapiBaseURL + "/pokemon/pidgey": `{
+		"id": 16,
+		"name": "pidgey",
+		"base_experience": 50,
+		"height": 3,
+		"weight": 18,
+		"stats": [{"base_stat": 40, "stat": {"name": "hp"}}],
+		"types": [{"type": {"name": "normal"}}, {"type": {"name": "flying"}}],
+		"species": {"name": "pidgey", "url": ""}
+	}`,
+	pokeapiBaseURL + "/type/electric": `{
+		"name": "electric",
+		"damage_relations": {
+			"double_damage_to": [{"name": "flying"}, {"name": "water"}],
+			"half_damage_to": [{"name": "grass"}, {"name": "electric"}, {"name": "dragon"}],
+			"no_damage_to": [{"name": "ground"}],
+			"double_damage_from": [{"name": "ground"}],
+			"half_damage_from": [{"name": "flying"}, {"name": "steel"}, {"name": "electric"}],
+			"no_damage_from": []
+		}
+	}`,
+	pokeapiBaseURL + "/type/normal": `{
+		"name": "normal",
+		"damage_relations": {
+			"double_damage_to": [],
+			"half_damage_to": [{"name": "rock"}, {"name": "steel"}],
+			"no_damage_to": [{"name": "ghost"}],
+			"double_damage_from": [{"name": "fighting"}],
+			"half_damage_from": [],
+			"no_damage_from": [{"name": "ghost"}]
+		}
+	}`,
+	pokeapiBaseURL + "/type/flying": `{
+		"name": "flying",
+		"damage_relations": {
+			"double_damage_to": [{"name": "fighting"}, {"name": "bug"}, {"name": "grass"}],
+			"half_damage_to": [{"name": "rock"}, {"name": "steel"}, {"name": "electric"}],
+			"no_damage_to": [],
+			"double_damage_from": [{"name": "rock"}, {"name": "electric"}, {"name": "ice"}],
+			"half_damage_from": [{"name": "fighting"}, {"name": "bug"}, {"name": "grass"}],
+			"no_damage_from": [{"name": "ground"}]
+		}
+	}`,
+	pokeapiBaseURL + "/item?limit=2&offset=0": `{
+		"count": 3,
+		"next": "https://pokeapi.co/api/v2/item?offset=2&limit=2",
+		"previous": null,
+		"results": [{"name": "master-ball"}, {"name": "ultra-ball"}]
+	}`,
+	pokeapiBaseURL + "/item?limit=2&offset=2": `{
+		"count": 3,
+		"next": null,
+		"previous": "https://pokeapi.co/api/v2/item?offset=0&limit=2",
+		"results": [{"name": "great-ball"}]
+	}`,
+	pokeapiBaseURL + "/item-category/healing": `{
+		"name": "healing",
+		"items": [{"name": "potion"}, {"name": "super-potion"}, {"name": "hyper-potion"}],
+		"pocket": {"name": "medicine"}
+	}`,
+	pokeapiBaseURL + "/item/potion": `{
+		"name": "potion",
+		"cost": 200,
+		"fling_power": 30,
+		"fling_effect": null,
+		"category": {"name": "healing"},
+		"attributes": [{"name": "countable"}, {"name": "consumable"}, {"name": "usable-overworld"}],
+		"effect_entries": [{"short_effect": "Restores 20 HP.", "language": {"name": "en"}}]
+	}`,
+	pokeapiBaseURL + "/item/cheri-berry": `{
+		"name": "cheri-berry",
+		"cost": 80,
+		"fling_power": 10,
+		"fling_effect": {"name": "berry-effect"},
+		"category": {"name": "medicine"},
+		"attributes": [{"name": "holdable"}, {"name": "consumable"}],
+		"effect_entries": [{"short_effect": "Holder cures paralysis.", "language": {"name": "en"}}]
+	}`,
+	pokeapiBaseURL + "/berry?limit=20&offset=0": `{
+		"count": 2,
+		"results": [{"name": "cheri"}, {"name": "chesto"}]
+	}`,
+	pokeapiBaseURL + "/berry/cheri": `{
+		"name": "cheri",
+		"growth_time": 3,
+		"max_harvest": 5,
+		"natural_gift_power": 60,
+		"natural_gift_type": {"name": "fire"},
+		"size": 20,
+		"smoothness": 25,
+		"firmness": {"name": "soft"},
+		"flavors": [
+			{"potency": 10, "flavor": {"name": "spicy"}},
+			{"potency": 0, "flavor": {"name": "dry"}}
+		],
+		"item": {"name": "cheri-berry"}
+	}`,
+	pokeapiBaseURL + "/pokemon-species/pikachu": `{
+		"id": 25,
+		"name": "pikachu",
+		"genera": [
+			{"genus": "ねずみポケモン", "language": {"name": "ja"}},
+			{"genus": "Mouse Pokémon", "language": {"name": "en"}},
+			{"genus": "Pokémon Souris", "language": {"name": "fr"}}
+		],
+		"flavor_text_entries": [
+			{"flavor_text": "When several of\nthese POKéMON\ngather, their\felectricity could\nbuild and cause\nlightning storms.", "language": {"name": "en"}, "version": {"name": "red"}},
+			{"flavor_text": "It keeps its tail raised to monitor its surroundings. If you yank its tail, it will try to bite you.", "language": {"name": "en"}, "version": {"name": "shield"}},
+			{"flavor_text": "Il garde sa queue levée pour surveiller les environs.", "language": {"name": "fr"}, "version": {"name": "shield"}}
+		],
+		"habitat": {"name": "forest", "url": ""},
+		"color": {"name": "yellow", "url": ""},
+		"shape": {"name": "quadruped", "url": ""},
+		"generation": {"name": "generation-i", "url": ""},
+		"is_legendary": false,
+		"is_mythical": false,
+		"egg_groups": [{"name": "ground", "url": ""}, {"name": "fairy", "url": ""}],
+		"evolution_chain": {"url": "` + pokeapiBaseURL + `/evolution-chain/10/"}
+	}`,
+	pokeapiBaseURL + "/evolution-chain/10/": `{
+		"id": 10,
+		"chain": {
+			"species": {"name": "pichu"},
+			"is_baby": true,
+			"evolution_details": [],
+			"evolves_to": [{
+				"species": {"name": "pikachu"},
+				"evolution_details": [{"trigger": {"name": "level-up"}, "min_happiness": 220, "time_of_day": ""}],
+				"evolves_to": [
+					{
+						"species": {"name": "raichu"},
+						"evolution_details": [{"trigger": {"name": "use-item"}, "item": {"name": "thunder-stone"}, "time_of_day": ""}],
+						"evolves_to": []
+					},
+					{
+						"species": {"name": "raichu-alola"},
+						"evolution_details": [{"trigger": {"name": "use-item"}, "item": {"name": "thunder-stone"}, "location": {"name": "alola"}, "time_of_day": ""}],
+						"evolves_to": []
+					}
+				]
+			}]
+		}
+	}`,
+	fmt.Sprintf("%s/pokemon?limit=%d", pokeapiBaseURL, nameIndexLimit): `{
+		"count": 3,
+		"results": [{"name": "pikachu"}, {"name": "raichu"}, {"name": "pidgey"}]
+	}`,
+	fmt.Sprintf("%s/location-area?limit=%d", pokeapiBaseURL, nameIndexLimit): `{
+		"count": 2,
+		"results": [{"name": "pallet-town-area"}, {"name": "viridian-forest-area"}]
+	}`,
+}
+
+const pikachuSpriteURL = "https://raw.githubusercontent.com/PokeAPI/sprites/master/sprites/pokemon/25.png"
+
+// newTestConfig returns a config backed by testResponses whose output and
+// diagnostics are captured in the returned buffers.
+func newTestConfig() (*config, *bytes.Buffer, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	diag := &bytes.Buffer{}
+	cfg := &config{
+		pokeapiClient:   pokecache.NewCache(5 * time.Minute),
+		nextLocationURL: pokeapiBaseURL + "/location-area",
+		caughtPokemon:   make(map[string]ownedPokemon),
+		out:             out,
+		diag:            diag,
+	}
+	for url, body := range testResponses {
+		cfg.pokeapiClient.Add(url, []byte(body))
+	}
+	return cfg, out, diag
+}
+
+// assertGolden compares actual with testdata/name.golden, rewriting the file
+// instead when the tests run with -update.
+func assertGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("output does not match %s\ngot:\n%s\nexpected:\n%s", path, actual, expected)
+	}
+}
+
+func TestCommandOutput_Golden(t *testing.T) {
+	cases := []struct {
+		name   string
+		setup  func(cfg *config)
+		format outputFormat
+		// colorTerm is COLORTERM for the case, so colors do not depend on
+		// the terminal running the tests.
+		colorTerm string
+		input     []string
+	}{
+		{name: "help", input: []string{"help"}},
+		{name: "help_map", input: []string{"help map"}},
+		{name: "help_explore", input: []string{"help explore"}},
+		{name: "map", input: []string{"map"}},
+		{name: "map_last_page", setup: func(cfg *config) { cfg.nextLocationURL = "" }, input: []string{"map"}},
+		{name: "mapb_first_page", input: []string{"mapb"}},
+		{name: "regions", input: []string{"regions"}},
+		{name: "region", input: []string{"region kanto"}},
+		{name: "locations", input: []string{"region kanto", "locations"}},
+		{name: "areas", input: []string{"areas pallet-town"}},
+		{name: "explore", input: []string{"explore pallet-town-area"}},
+		{name: "explore_json", format: outputJSON, input: []string{"explore pallet-town-area"}},
+		{name: "explore_yaml", format: outputYAML, input: []string{"explore pallet-town-area"}},
+		{name: "explore_table", format: outputTable, input: []string{"explore pallet-town-area"}},
+		{
+			name:  "catch_caught",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"catch pikachu"},
+		},
+		{
+			name:  "catch_nickname",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{`CATCH Pikachu --nickname "Sparky Jr"`, `inspect "sparky jr"`},
+		},
+		{
+			name:  "catch_escaped",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 100 } },
+			input: []string{"catch pikachu"},
+		},
+		{
+			name:  "inspect",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"catch pikachu", "inspect pikachu"},
+		},
+		{
+			name:  "inspect_not_caught",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"catch pikachu", "inspect pikchu"},
+		},
+		{name: "pokedex_empty", input: []string{"pokedex"}},
+		{
+			name:  "pokedex",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"catch pikachu", "pokedex"},
+		},
+		{
+			name: "history",
+			setup: func(cfg *config) {
+				cfg.history, _ = loadHistory("", 0)
+				cfg.history.Add("map")
+				cfg.history.Add("explore pallet-town-area")
+			},
+			input: []string{"history"},
+		},
+		{name: "search", input: []string{"search chu"}},
+		{name: "help_alias", input: []string{"help e"}},
+		{name: "species", input: []string{"species Pikachu"}},
+		{name: "evolutions", input: []string{"evolutions pikachu"}},
+		{
+			name:  "evolve_not_ready",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"catch pikachu --nickname Sparky", "evolve sparky"},
+		},
+		{
+			name:  "evolve",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"catch pikachu --nickname Sparky", "evolve sparky --item thunder-stone", "inspect sparky"},
+		},
+		{
+			name:  "train_and_give",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"catch pikachu", "train pikachu --levels 7", "give pikachu light-ball", "inspect pikachu"},
+		},
+		{name: "types", input: []string{"types electric"}},
+		{name: "types_dual", input: []string{"types Normal Flying"}},
+		{name: "types_table", format: outputTable, input: []string{"types electric"}},
+		{name: "matchup", input: []string{"matchup pikachu vs pidgey"}},
+		{name: "compare", input: []string{"compare pikachu raichu pidgey"}},
+		{
+			name:   "compare_json",
+			format: outputJSON,
+			setup:  func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input:  []string{"catch pikachu --nickname Sparky", "compare sparky pidgey"},
+		},
+		{
+			name: "compare_color",
+			setup: func(cfg *config) {
+				cfg.settings = defaultSettings()
+				cfg.settings.Color = colorAlways
+			},
+			input: []string{"compare pikachu raichu", "compare raichu pikachu"},
+		},
+		{
+			name:   "matchup_json",
+			format: outputJSON,
+			setup:  func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input:  []string{"catch pikachu --nickname Sparky", "matchup pidgey sparky"},
+		},
+		{name: "moves", input: []string{"moves pikachu"}},
+		{name: "moves_level_up", input: []string{"moves pikachu --method level-up --version-group red-blue"}},
+		{name: "moves_table", format: outputTable, input: []string{"moves pikachu --method machine"}},
+		{name: "move", input: []string{"move Thunderbolt"}},
+		{name: "move_priority", input: []string{"move quick-attack"}},
+		{name: "move_json", format: outputJSON, input: []string{"move growl"}},
+		{name: "ability", input: []string{"ability static"}},
+		{
+			name:  "ability_language",
+			setup: func(cfg *config) { cfg.settings = defaultSettings(); cfg.settings.Language = "fr" },
+			input: []string{"ability static"},
+		},
+		{name: "items", input: []string{"items --limit 2", "items", "items"}},
+		{name: "items_previous", input: []string{"items --previous", "items --limit 2", "items", "items --previous", "items --previous"}},
+		{name: "items_category", input: []string{"items healing --limit 2", "items healing", "items healing --page 1"}},
+		{name: "items_category_previous", input: []string{"items healing --limit 2", "items healing", "items healing --previous"}},
+		{name: "item", input: []string{"item Potion"}},
+		{name: "item_json", format: outputJSON, input: []string{"item potion"}},
+		{name: "berries", format: outputTable, input: []string{"berries"}},
+		{name: "berry", input: []string{"berry cheri-berry"}},
+		{
+			name:  "pokedex_region",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"catch pikachu --nickname Sparky", "explore pallet-town-area", "pokedex --region Kanto"},
+		},
+		{
+			name:  "pokedex_seen",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"explore pallet-town-area", "catch pikachu", "pokedex"},
+		},
+		{
+			name:   "pokedex_seen_table",
+			format: outputTable,
+			setup:  func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input:  []string{"explore pallet-town-area", "catch pikachu", "pokedex"},
+		},
+		{
+			name:  "inspect_seen",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 100 } },
+			input: []string{"catch pikachu", "inspect pikachu", "inspect raichu"},
+		},
+		{
+			name:  "pokedex_sort",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"catch pikachu", "catch pidgey", "catch raichu", "pokedex", "pokedex --sort total-stats", "pokedex --sort name --reverse"},
+		},
+		{
+			name:  "pokedex_query",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{
+				"catch pikachu --nickname Sparky", "catch pidgey --nickname 'Mr Mime'", "catch raichu",
+				"pokedex --type electric --stat speed>100",
+				"pokedex --nickname sparky",
+				"pokedex --nickname SPARKY --type Electric",
+				"pokedex --nickname 'Mr Mime'",
+				"pokedex --gen I --type Flying",
+				"pokedex where type=electric and speed > 100 or type=flying",
+				"pokedex where gen=ii",
+			},
+		},
+		{name: "pokedex_generation", input: []string{"pokedex --generation 2"}},
+		{name: "pokedex_generation_json", format: outputJSON, input: []string{"pokedex --generation ii"}},
+		{name: "evolutions_table", format: outputTable, input: []string{"evolutions pikachu"}},
+		{name: "evolutions_json", format: outputJSON, input: []string{"evolutions pikachu"}},
+		{name: "species_version", input: []string{"species pikachu --version red"}},
+		{name: "species_language", input: []string{"species pikachu --language fr"}},
+		{
+			name:   "species_yaml",
+			format: outputYAML,
+			input:  []string{"species pikachu"},
+		},
+		{
+			name:  "inspect_full",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{"catch pikachu", "inspect pikachu --full"},
+		},
+		{
+			name: "inspect_sprite",
+			setup: func(cfg *config) {
+				cfg.rollPercent = func() int { return 1 }
+				png, _ := os.ReadFile(filepath.Join("internal", "sprite", "testdata", "tiny.png"))
+				cfg.pokeapiClient.Add(pikachuSpriteURL, png)
+			},
+			input: []string{"catch pikachu", "inspect pikachu --sprite"},
+		},
+		{
+			name: "inspect_sprite_json",
+			setup: func(cfg *config) {
+				cfg.rollPercent = func() int { return 1 }
+				png, _ := os.ReadFile(filepath.Join("internal", "sprite", "testdata", "tiny.png"))
+				cfg.pokeapiClient.Add(pikachuSpriteURL, png)
+			},
+			format: outputJSON,
+			input:  []string{"catch pikachu", "inspect pikachu --sprite"},
+		},
+		{
+			name: "inspect_color",
+			setup: func(cfg *config) {
+				cfg.rollPercent = func() int { return 1 }
+				cfg.settings = defaultSettings()
+				cfg.settings.Color = colorAlways
+			},
+			input: []string{"catch pikachu --nickname Sparky", "inspect pikachu"},
+		},
+		{
+			name: "inspect_color_truecolor",
+			setup: func(cfg *config) {
+				cfg.rollPercent = func() int { return 1 }
+				cfg.settings = defaultSettings()
+				cfg.settings.Color = colorAlways
+			},
+			colorTerm: "truecolor",
+			input:     []string{"catch pikachu", "inspect pikachu"},
+		},
+		{
+			name: "catch_escaped_color",
+			setup: func(cfg *config) {
+				cfg.rollPercent = func() int { return 100 }
+				cfg.settings = defaultSettings()
+				cfg.settings.Color = colorAlways
+			},
+			input: []string{"catch pikachu"},
+		},
+		{
+			name: "catch_json_color",
+			setup: func(cfg *config) {
+				cfg.rollPercent = func() int { return 100 }
+				cfg.settings = defaultSettings()
+				cfg.settings.Color = colorAlways
+			},
+			format: outputJSON,
+			input:  []string{"catch pikachu"},
+		},
+		{
+			name: "config",
+			setup: func(cfg *config) {
+				cfg.settings = defaultSettings()
+				cfg.settings.ConfigPath = "/home/ash/.config/pokedex/config.json"
+				cfg.settings.DataDir = "/home/ash/.local/share/pokedex"
+				cfg.settings.Prompt = "ash> "
+				cfg.settings.sources["prompt"] = "config file"
+				cfg.settings.CacheInterval = time.Hour
+				cfg.settings.sources["cache_interval"] = "env POKEDEX_CACHE_INTERVAL"
+			},
+			input: []string{"config"},
+		},
+		{
+			name:  "alias_macro",
+			setup: func(cfg *config) { cfg.rollPercent = func() int { return 1 } },
+			input: []string{`alias hunt = "explore $1; c $2 --nickname $3"`, "hunt pallet-town-area pikachu Sparky", "ls", "alias"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv("COLORTERM", c.colorTerm)
+			cfg, out, _ := newTestConfig()
+			cfg.outputFormat = c.format
+			if c.setup != nil {
+				c.setup(cfg)
+			}
+
+			for _, line := range c.input {
+				if err := executeInput(cfg, line); err != nil {
+					t.Fatalf("executeInput(%q) returned unexpected error: %v", line, err)
+				}
+			}
+
+			assertGolden(t, c.name, out.Bytes())
+		})
+	}
+}
+
+func TestFetchResource_DiagnosticsStayOffOutput(t *testing.T) {
+	cfg, out, diag := newTestConfig()
+
+	if err := executeInput(cfg, "explore pallet-town-area"); err != nil {
+		t.Fatalf("executeInput returned unexpected error: %v", err)
+	}
+
+	if strings.Contains(out.String(), "Using cached data") {
+		t.Errorf("expected cache messages to stay off the output, got %q", out.String())
+	}
+	expected := "Using cached data for " + pokeapiBaseURL + "/location-area/pallet-town-area\n" +
+		"Using cached data for " + pokeapiBaseURL + "/location/pallet-town\n"
+	if diag.String() != expected {
+		t.Errorf("diagnostics = %q, expected %q", diag.String(), expected)
+	}
+}
+
+func TestRenderResult(t *testing.T) {
+	result := pokedexResult{Pokemon: []string{"pidgey", "caterpie"}}
+
+	cases := []struct {
+		format   outputFormat
+		expected string
+	}{
+		{format: outputText, expected: "Your Pokedex:\n - pidgey\n - caterpie\n"},
+		{format: outputTable, expected: "POKEMON   STATUS\npidgey    caught\ncaterpie  caught\n"},
+		{format: outputJSON, expected: "{\n  \"pokemon\": [\n    \"pidgey\",\n    \"caterpie\"\n  ]\n}\n"},
+		{format: outputYAML, expected: "pokemon:\n  - pidgey\n  - caterpie\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := renderResult(&buf, c.format, result, false); err != nil {
+				t.Fatalf("renderResult returned unexpected error: %v", err)
+			}
+			if buf.String() != c.expected {
+				t.Errorf("renderResult output %q, expected %q", buf.String(), c.expected)
+			}
+		})
+	}
+}
+
+func TestRenderResult_TableFallsBackToText(t *testing.T) {
+	var buf bytes.Buffer
+	err := renderResult(&buf, outputTable, catchResult{Pokemon: "pikachu", Caught: false}, false)
+	if err != nil {
+		t.Fatalf("renderResult returned unexpected error: %v", err)
+	}
+
+	expected := "Throwing a Pokeball at pikachu...\npikachu escaped!\n"
+	if buf.String() != expected {
+		t.Errorf("renderResult output %q, expected %q", buf.String(), expected)
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, valid := range []string{"json", "yaml", "table", "text"} {
+		if _, err := parseOutputFormat(valid); err != nil {
+			t.Errorf("parseOutputFormat(%q) returned unexpected error: %v", valid, err)
+		}
+	}
+
+	if _, err := parseOutputFormat("xml"); err == nil {
+		t.Error("expected error for unknown output format")
+	}
+}
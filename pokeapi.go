@@ -0,0 +1,427 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// pokeapiBaseURL is the PokeAPI server used unless api_base_url is set.
+const pokeapiBaseURL = "https://pokeapi.co/api/v2"
+
+// defaultPageLimit is the page size PokeAPI uses when none is requested.
+const defaultPageLimit = 20
+
+// nameIndexLimit is large enough to list every resource of a kind in one page.
+const nameIndexLimit = 100000
+
+// errNotFound is returned when PokeAPI has no resource at the requested URL.
+var errNotFound = errors.New("not found")
+
+type namedAPIResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type locationAreasResp struct {
+	Count    int     `json:"count"`
+	Next     string  `json:"next"`
+	Previous *string `json:"previous"`
+	Results  []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"results"`
+}
+
+type locationAreaResp struct {
+	ID                int              `json:"id"`
+	Name              string           `json:"name"`
+	GameIndex         int              `json:"game_index"`
+	Location          namedAPIResource `json:"location"`
+	PokemonEncounters []struct {
+		Pokemon struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon"`
+	} `json:"pokemon_encounters"`
+}
+
+type namedAPIResourceList struct {
+	Count    int                `json:"count"`
+	Next     *string            `json:"next"`
+	Previous *string            `json:"previous"`
+	Results  []namedAPIResource `json:"results"`
+}
+
+type regionResp struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	Locations      []namedAPIResource `json:"locations"`
+	MainGeneration namedAPIResource   `json:"main_generation"`
+	Pokedexes      []namedAPIResource `json:"pokedexes"`
+}
+
+type pokedexResp struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	PokemonEntries []struct {
+		EntryNumber    int              `json:"entry_number"`
+		PokemonSpecies namedAPIResource `json:"pokemon_species"`
+	} `json:"pokemon_entries"`
+}
+
+type generationResp struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	MainRegion     namedAPIResource   `json:"main_region"`
+	PokemonSpecies []namedAPIResource `json:"pokemon_species"`
+}
+
+type locationResp struct {
+	ID     int                `json:"id"`
+	Name   string             `json:"name"`
+	Region namedAPIResource   `json:"region"`
+	Areas  []namedAPIResource `json:"areas"`
+}
+
+type Pokemon struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	BaseExperience int    `json:"base_experience"`
+	Height         int    `json:"height"`
+	Weight         int    `json:"weight"`
+	Stats          []struct {
+		BaseStat int `json:"base_stat"`
+		Stat     struct {
+			Name string `json:"name"`
+		} `json:"stat"`
+	} `json:"stats"`
+	Types []struct {
+		Type struct {
+			Name string `json:"name"`
+		} `json:"type"`
+	} `json:"types"`
+	Abilities []struct {
+		Ability  namedAPIResource `json:"ability"`
+		IsHidden bool             `json:"is_hidden"`
+		Slot     int              `json:"slot"`
+	} `json:"abilities"`
+	Moves []struct {
+		Move                namedAPIResource `json:"move"`
+		VersionGroupDetails []struct {
+			LevelLearnedAt  int              `json:"level_learned_at"`
+			MoveLearnMethod namedAPIResource `json:"move_learn_method"`
+			VersionGroup    namedAPIResource `json:"version_group"`
+		} `json:"version_group_details"`
+	} `json:"moves"`
+	Species namedAPIResource `json:"species"`
+	Sprites struct {
+		FrontDefault string `json:"front_default"`
+	} `json:"sprites"`
+}
+
+type pokemonSpeciesResp struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Genera []struct {
+		Genus    string           `json:"genus"`
+		Language namedAPIResource `json:"language"`
+	} `json:"genera"`
+	FlavorTextEntries []flavorTextEntry  `json:"flavor_text_entries"`
+	Habitat           *namedAPIResource  `json:"habitat"`
+	Color             namedAPIResource   `json:"color"`
+	Shape             *namedAPIResource  `json:"shape"`
+	Generation        namedAPIResource   `json:"generation"`
+	IsLegendary       bool               `json:"is_legendary"`
+	IsMythical        bool               `json:"is_mythical"`
+	EggGroups         []namedAPIResource `json:"egg_groups"`
+	EvolutionChain    struct {
+		URL string `json:"url"`
+	} `json:"evolution_chain"`
+	Varieties []struct {
+		IsDefault bool             `json:"is_default"`
+		Pokemon   namedAPIResource `json:"pokemon"`
+	} `json:"varieties"`
+}
+
+type typeResp struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	DamageRelations struct {
+		DoubleDamageTo   []namedAPIResource `json:"double_damage_to"`
+		HalfDamageTo     []namedAPIResource `json:"half_damage_to"`
+		NoDamageTo       []namedAPIResource `json:"no_damage_to"`
+		DoubleDamageFrom []namedAPIResource `json:"double_damage_from"`
+		HalfDamageFrom   []namedAPIResource `json:"half_damage_from"`
+		NoDamageFrom     []namedAPIResource `json:"no_damage_from"`
+	} `json:"damage_relations"`
+}
+
+type moveResp struct {
+	ID            int              `json:"id"`
+	Name          string           `json:"name"`
+	Power         *int             `json:"power"`
+	Accuracy      *int             `json:"accuracy"`
+	PP            *int             `json:"pp"`
+	Priority      int              `json:"priority"`
+	EffectChance  *int             `json:"effect_chance"`
+	DamageClass   namedAPIResource `json:"damage_class"`
+	Type          namedAPIResource `json:"type"`
+	Generation    namedAPIResource `json:"generation"`
+	EffectEntries []effectEntry    `json:"effect_entries"`
+}
+
+type abilityResp struct {
+	ID            int              `json:"id"`
+	Name          string           `json:"name"`
+	Generation    namedAPIResource `json:"generation"`
+	EffectEntries []effectEntry    `json:"effect_entries"`
+	Pokemon       []struct {
+		IsHidden bool             `json:"is_hidden"`
+		Pokemon  namedAPIResource `json:"pokemon"`
+	} `json:"pokemon"`
+}
+
+type effectEntry struct {
+	Effect      string           `json:"effect"`
+	ShortEffect string           `json:"short_effect"`
+	Language    namedAPIResource `json:"language"`
+}
+
+type itemResp struct {
+	ID            int                `json:"id"`
+	Name          string             `json:"name"`
+	Cost          int                `json:"cost"`
+	FlingPower    *int               `json:"fling_power"`
+	FlingEffect   *namedAPIResource  `json:"fling_effect"`
+	Category      namedAPIResource   `json:"category"`
+	Attributes    []namedAPIResource `json:"attributes"`
+	EffectEntries []effectEntry      `json:"effect_entries"`
+}
+
+type itemCategoryResp struct {
+	ID     int                `json:"id"`
+	Name   string             `json:"name"`
+	Items  []namedAPIResource `json:"items"`
+	Pocket namedAPIResource   `json:"pocket"`
+}
+
+type berryResp struct {
+	ID               int              `json:"id"`
+	Name             string           `json:"name"`
+	GrowthTime       int              `json:"growth_time"`
+	MaxHarvest       int              `json:"max_harvest"`
+	NaturalGiftPower int              `json:"natural_gift_power"`
+	NaturalGiftType  namedAPIResource `json:"natural_gift_type"`
+	Size             int              `json:"size"`
+	Smoothness       int              `json:"smoothness"`
+	Firmness         namedAPIResource `json:"firmness"`
+	Flavors          []struct {
+		Potency int              `json:"potency"`
+		Flavor  namedAPIResource `json:"flavor"`
+	} `json:"flavors"`
+	Item namedAPIResource `json:"item"`
+}
+
+type flavorTextEntry struct {
+	FlavorText string           `json:"flavor_text"`
+	Language   namedAPIResource `json:"language"`
+	Version    namedAPIResource `json:"version"`
+}
+
+// fetchResource returns the decoded body of url, serving it from the cache
+// when possible and caching the raw response otherwise.
+func fetchResource[T any](cfg *config, url string) (T, error) {
+	return fetchResourceLogged[T](cfg, url, cfg.diagWriter())
+}
+
+// fetchResourceLogged is fetchResource with the cache and request messages
+// written to logw, so background lookups can discard them.
+func fetchResourceLogged[T any](cfg *config, url string, logw io.Writer) (T, error) {
+	var resource T
+
+	dat, err := fetchBytes(cfg, url, logw)
+	if err != nil {
+		return resource, err
+	}
+
+	err = json.Unmarshal(dat, &resource)
+	if err != nil {
+		return resource, err
+	}
+
+	return resource, nil
+}
+
+// fetchBytes returns the body at url, from the cache when it is there and
+// from the network otherwise. Responses are only cached once they have been
+// read in full.
+func fetchBytes(cfg *config, url string, logw io.Writer) ([]byte, error) {
+	// Check if we have the data in cache
+	if val, ok := cfg.pokeapiClient.Get(url); ok {
+		fmt.Fprintf(logw, "Using cached data for %s\n", url)
+		return val, nil
+	}
+
+	fmt.Fprintf(logw, "Making HTTP request to %s\n", url)
+	cfg.requestStarted()
+	defer cfg.requestFinished()
+	res, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("unexpected response %s from %s", res.Status, url)
+	}
+
+	dat, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add to cache
+	cfg.pokeapiClient.Add(url, dat)
+
+	return dat, nil
+}
+
+// locationAreasPageURL returns the URL of the location area list starting at
+// offset with limit entries per page.
+func locationAreasPageURL(cfg *config, offset, limit int) string {
+	return listPageURL(cfg, "location-area", offset, limit)
+}
+
+// listPageURL is the URL of one page of a PokeAPI resource list.
+func listPageURL(cfg *config, resource string, offset, limit int) string {
+	query := url.Values{}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	return cfg.apiURL() + "/" + resource + "?" + query.Encode()
+}
+
+// pageBounds extracts the offset and limit query parameters of a list URL,
+// falling back to PokeAPI's defaults when they are missing or invalid.
+func pageBounds(pageURL string) (offset, limit int) {
+	limit = defaultPageLimit
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return 0, limit
+	}
+
+	query := parsed.Query()
+	if n, err := strconv.Atoi(query.Get("limit")); err == nil && n > 0 {
+		limit = n
+	}
+	if n, err := strconv.Atoi(query.Get("offset")); err == nil && n >= 0 {
+		offset = n
+	}
+	return offset, limit
+}
+
+func (cfg *config) requestStarted() {
+	cfg.pendingRequests.Add(1)
+	if cfg.onRequestsChanged != nil {
+		cfg.onRequestsChanged()
+	}
+}
+
+func (cfg *config) requestFinished() {
+	cfg.pendingRequests.Add(-1)
+	if cfg.onRequestsChanged != nil {
+		cfg.onRequestsChanged()
+	}
+}
+
+// apiURL returns the base URL of the PokeAPI server in use.
+func (cfg *config) apiURL() string {
+	return cfg.currentSettings().APIBaseURL
+}
+
+func getLocationAreas(cfg *config, pageURL string) (locationAreasResp, error) {
+	return fetchResource[locationAreasResp](cfg, pageURL)
+}
+
+func getLocationArea(cfg *config, locationAreaName string) (locationAreaResp, error) {
+	return fetchResource[locationAreaResp](cfg, cfg.apiURL()+"/location-area/"+locationAreaName)
+}
+
+func getPokemon(cfg *config, pokemonName string) (Pokemon, error) {
+	return fetchResource[Pokemon](cfg, cfg.apiURL()+"/pokemon/"+pokemonName)
+}
+
+func getPokemonSpecies(cfg *config, speciesName string) (pokemonSpeciesResp, error) {
+	return fetchResource[pokemonSpeciesResp](cfg, cfg.apiURL()+"/pokemon-species/"+speciesName)
+}
+
+func getType(cfg *config, typeName string) (typeResp, error) {
+	return fetchResource[typeResp](cfg, cfg.apiURL()+"/type/"+typeName)
+}
+
+func getMove(cfg *config, moveName string) (moveResp, error) {
+	return fetchResource[moveResp](cfg, cfg.apiURL()+"/move/"+moveName)
+}
+
+func getAbility(cfg *config, abilityName string) (abilityResp, error) {
+	return fetchResource[abilityResp](cfg, cfg.apiURL()+"/ability/"+abilityName)
+}
+
+func getItem(cfg *config, itemName string) (itemResp, error) {
+	return fetchResource[itemResp](cfg, cfg.apiURL()+"/item/"+itemName)
+}
+
+func getItemCategory(cfg *config, categoryName string) (itemCategoryResp, error) {
+	return fetchResource[itemCategoryResp](cfg, cfg.apiURL()+"/item-category/"+categoryName)
+}
+
+func getBerry(cfg *config, berryName string) (berryResp, error) {
+	return fetchResource[berryResp](cfg, cfg.apiURL()+"/berry/"+berryName)
+}
+
+func getPokedex(cfg *config, pokedexName string) (pokedexResp, error) {
+	return fetchResource[pokedexResp](cfg, cfg.apiURL()+"/pokedex/"+pokedexName)
+}
+
+func getGeneration(cfg *config, generationName string) (generationResp, error) {
+	return fetchResource[generationResp](cfg, cfg.apiURL()+"/generation/"+generationName)
+}
+
+func getRegions(cfg *config) (namedAPIResourceList, error) {
+	return fetchResource[namedAPIResourceList](cfg, cfg.apiURL()+"/region")
+}
+
+func getRegion(cfg *config, regionName string) (regionResp, error) {
+	return fetchResource[regionResp](cfg, cfg.apiURL()+"/region/"+regionName)
+}
+
+func getLocation(cfg *config, locationName string) (locationResp, error) {
+	return fetchResource[locationResp](cfg, cfg.apiURL()+"/location/"+locationName)
+}
+
+// getNameIndex returns the names of every resource of the given kind, such as
+// "pokemon" or "location-area".
+func getNameIndex(cfg *config, resource string) ([]string, error) {
+	return getNameIndexLogged(cfg, resource, cfg.diagWriter())
+}
+
+func getNameIndexLogged(cfg *config, resource string, logw io.Writer) ([]string, error) {
+	indexURL := fmt.Sprintf("%s/%s?limit=%d", cfg.apiURL(), resource, nameIndexLimit)
+	list, err := fetchResourceLogged[namedAPIResourceList](cfg, indexURL, logw)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Results))
+	for _, result := range list.Results {
+		names = append(names, result.Name)
+	}
+	return names, nil
+}
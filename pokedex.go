@@ -0,0 +1,466 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type pokedexResult struct {
+	Pokemon []string `json:"pokemon"`
+	// Seen lists the Pokemon seen but not caught.
+	Seen []string `json:"seen,omitempty"`
+	// filtered is set when filters chose which caught Pokemon to list.
+	filtered bool
+}
+
+func (r pokedexResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "Your Pokedex:")
+
+	switch {
+	case len(r.Pokemon) == 0 && r.filtered:
+		fmt.Fprintln(w, " (No caught Pokemon match)")
+	case len(r.Pokemon) == 0:
+		fmt.Fprintln(w, " (No Pokemon caught yet)")
+	}
+	for _, pokemonName := range r.Pokemon {
+		fmt.Fprintf(w, " - %s\n", pokemonName)
+	}
+
+	if len(r.Seen) > 0 {
+		fmt.Fprintln(w, "Seen:")
+		for _, pokemonName := range r.Seen {
+			fmt.Fprintf(w, " - %s\n", pokemonName)
+		}
+	}
+}
+
+func (r pokedexResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, name := range r.Pokemon {
+		rows = append(rows, []string{name, "caught"})
+	}
+	for _, name := range r.Seen {
+		rows = append(rows, []string{name, "seen"})
+	}
+	return []string{"pokemon", "status"}, rows
+}
+
+func commandPokedex(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	switch {
+	case flags.has("region") && flags.has("generation"):
+		return nil, fmt.Errorf("--region and --generation cannot be used together")
+	case flags.has("region"):
+		return regionProgress(cfg, apiName(flags.get("region")))
+	case flags.has("generation"):
+		return generationProgress(cfg, flags.get("generation"))
+	}
+
+	query, err := pokedexFilters(flags, args)
+	if err != nil {
+		return nil, err
+	}
+	sortKey := flags.get("sort")
+	if sortKey == "" {
+		sortKey = "id"
+	}
+
+	caught := []ownedPokemon{}
+	for _, pokemon := range cfg.caughtPokemon {
+		ok, err := query.matches(pokemon)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			caught = append(caught, pokemon)
+		}
+	}
+	if err := sortPokedex(caught, sortKey, flags.has("reverse")); err != nil {
+		return nil, err
+	}
+
+	result := pokedexResult{Pokemon: []string{}, filtered: len(query) > 0}
+	for _, pokemon := range caught {
+		name := pokemon.Name
+		if pokemon.Nickname != "" {
+			name = fmt.Sprintf("%s (%s)", pokemon.Name, pokemon.Nickname)
+		}
+		result.Pokemon = append(result.Pokemon, name)
+	}
+
+	// Seen Pokemon have none of the data the filters look at
+	if !result.filtered {
+		result.Seen = []string{}
+		for pokemonName := range cfg.seenPokemon {
+			if _, caught := cfg.caughtPokemon[pokemonName]; !caught {
+				result.Seen = append(result.Seen, pokemonName)
+			}
+		}
+		sort.Strings(result.Seen)
+	}
+
+	return result, nil
+}
+
+// pokedexFilters combines the filter flags and a `where` query into one
+// query. The flags must all match, as must the query.
+func pokedexFilters(flags commandFlags, args []string) (pokedexQuery, error) {
+	filters := []pokedexCondition{}
+	for _, flag := range []struct{ name, field string }{
+		{"type", "type"},
+		{"gen", "generation"},
+		{"nickname", "nickname"},
+		{"caught-in", "caught-in"},
+	} {
+		if flags.has(flag.name) {
+			// Flags are normalized as the same condition in a query would be
+			condition := pokedexCondition{field: flag.field, op: "=", value: flags.get(flag.name)}
+			if err := condition.validate(); err != nil {
+				return nil, fmt.Errorf("--%s: %w", flag.name, err)
+			}
+			filters = append(filters, condition)
+		}
+	}
+	if flags.has("stat") {
+		condition, err := parsePokedexCondition(flags.get("stat"))
+		if err != nil {
+			return nil, err
+		}
+		if _, numeric := numericPokedexFields[condition.field]; !numeric {
+			return nil, fmt.Errorf("--stat needs a stat threshold such as speed>90")
+		}
+		filters = append(filters, condition)
+	}
+
+	if len(args) > 0 && !strings.EqualFold(args[0], "where") {
+		return nil, fmt.Errorf(`a query starts with "where", e.g. pokedex where type=fire and speed>90`)
+	}
+	query := pokedexQuery{}
+	if len(args) > 1 {
+		var err error
+		query, err = parsePokedexQuery(strings.Join(args[1:], " "))
+		if err != nil {
+			return nil, err
+		}
+	} else if len(args) == 1 {
+		return nil, fmt.Errorf("where needs a query, e.g. pokedex where type=fire and speed>90")
+	}
+
+	if len(filters) == 0 {
+		return query, nil
+	}
+	if len(query) == 0 {
+		return pokedexQuery{filters}, nil
+	}
+	// Distribute the filters over the alternatives of the query
+	combined := pokedexQuery{}
+	for _, all := range query {
+		combined = append(combined, append(append([]pokedexCondition{}, filters...), all...))
+	}
+	return combined, nil
+}
+
+// sortPokedex orders caught Pokemon by national Pokedex number, name, the
+// time they were caught or their base stat total, strongest first.
+func sortPokedex(pokemon []ownedPokemon, key string, reverse bool) error {
+	var less func(a, b ownedPokemon) bool
+	switch key {
+	case "id":
+		less = func(a, b ownedPokemon) bool { return pokedexNumber(a) < pokedexNumber(b) }
+	case "name":
+		less = func(a, b ownedPokemon) bool { return a.Name < b.Name }
+	case "caught-at":
+		less = func(a, b ownedPokemon) bool { return a.CaughtAt.Before(b.CaughtAt) }
+	case "total-stats":
+		less = func(a, b ownedPokemon) bool { return totalStats(a) > totalStats(b) }
+	default:
+		return fmt.Errorf("cannot sort by %q, use id, name, caught-at or total-stats", key)
+	}
+
+	sort.SliceStable(pokemon, func(i, j int) bool {
+		a, b := pokemon[i], pokemon[j]
+		if reverse {
+			a, b = b, a
+		}
+		if less(a, b) != less(b, a) {
+			return less(a, b)
+		}
+		// Ties, and Pokemon with the same species, keep a stable order
+		return a.Name < b.Name
+	})
+	return nil
+}
+
+// pokedexNumber is the national Pokedex number of a caught Pokemon's
+// species. Alternate forms have PokeAPI ids above 10000, so the Pokemon id
+// only stands in when the species is unknown.
+func pokedexNumber(pokemon ownedPokemon) int {
+	if n := nationalNumber(pokemon.Species); n > 0 {
+		return n
+	}
+	if pokemon.ID < 10000 {
+		return pokemon.ID
+	}
+	return 0
+}
+
+func totalStats(pokemon ownedPokemon) int {
+	total := 0
+	for _, stat := range pokemon.Stats {
+		total += stat.BaseStat
+	}
+	return total
+}
+
+// pokedexQuery is a query in disjunctive form: a Pokemon matches when all the
+// conditions of any one group match. An empty query matches everything.
+type pokedexQuery [][]pokedexCondition
+
+type pokedexCondition struct {
+	field string
+	op    string
+	value string
+}
+
+// numericPokedexFields are the fields compared as numbers, with how to read
+// them from a caught Pokemon. The stat names are those PokeAPI uses.
+var numericPokedexFields = map[string]func(ownedPokemon) int{
+	"id":              pokedexNumber,
+	"level":           func(p ownedPokemon) int { return p.Level },
+	"friendship":      func(p ownedPokemon) int { return p.Friendship },
+	"generation":      func(p ownedPokemon) int { return speciesGeneration(pokedexNumber(p)) },
+	"total":           totalStats,
+	"hp":              baseStat("hp"),
+	"attack":          baseStat("attack"),
+	"defense":         baseStat("defense"),
+	"special-attack":  baseStat("special-attack"),
+	"special-defense": baseStat("special-defense"),
+	"speed":           baseStat("speed"),
+}
+
+// textPokedexFields are the fields compared as text, with = and != only.
+// Each returns all the values a Pokemon has, such as both of its types.
+var textPokedexFields = map[string]func(ownedPokemon) []string{
+	"name":      func(p ownedPokemon) []string { return []string{p.Name} },
+	"nickname":  func(p ownedPokemon) []string { return []string{strings.ToLower(p.Nickname)} },
+	"caught-in": func(p ownedPokemon) []string { return []string{p.CaughtArea} },
+	"held-item": func(p ownedPokemon) []string { return []string{p.HeldItem} },
+	"type": func(p ownedPokemon) []string {
+		types := []string{}
+		for _, t := range p.Types {
+			types = append(types, t.Type.Name)
+		}
+		return types
+	},
+}
+
+// pokedexFieldAliases are other names accepted for fields.
+var pokedexFieldAliases = map[string]string{
+	"gen":         "generation",
+	"total-stats": "total",
+	"area":        "caught-in",
+	"location":    "caught-in",
+}
+
+func baseStat(name string) func(ownedPokemon) int {
+	return func(p ownedPokemon) int {
+		for _, stat := range p.Stats {
+			if stat.Stat.Name == name {
+				return stat.BaseStat
+			}
+		}
+		return 0
+	}
+}
+
+// parsePokedexQuery parses conditions joined by "and" and "or", where "and"
+// binds tighter, e.g. `type=fire and speed>90 or type=water`.
+func parsePokedexQuery(text string) (pokedexQuery, error) {
+	words, err := splitPokedexQuery(text)
+	if err != nil {
+		return nil, err
+	}
+
+	query := pokedexQuery{}
+	group := []pokedexCondition{}
+	for i := 0; ; {
+		if i+3 > len(words) {
+			return nil, fmt.Errorf("incomplete condition at the end of the query")
+		}
+		condition := pokedexCondition{field: words[i], op: words[i+1], value: words[i+2]}
+		if err := condition.validate(); err != nil {
+			return nil, err
+		}
+		group = append(group, condition)
+		i += 3
+
+		if i == len(words) {
+			break
+		}
+		switch strings.ToLower(words[i]) {
+		case "and":
+		case "or":
+			query = append(query, group)
+			group = []pokedexCondition{}
+		default:
+			return nil, fmt.Errorf("expected and or or, got %q", words[i])
+		}
+		i++
+	}
+	return append(query, group), nil
+}
+
+// parsePokedexCondition parses a single condition such as speed>90.
+func parsePokedexCondition(text string) (pokedexCondition, error) {
+	query, err := parsePokedexQuery(text)
+	if err != nil {
+		return pokedexCondition{}, err
+	}
+	if len(query) != 1 || len(query[0]) != 1 {
+		return pokedexCondition{}, fmt.Errorf("expected a single condition, got %q", text)
+	}
+	return query[0][0], nil
+}
+
+// splitPokedexQuery splits a query into fields, operators, values and the
+// words and and or, with or without spaces around the operators.
+func splitPokedexQuery(text string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case strings.ContainsRune("=!<>", r):
+			flush()
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+				i++
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unknown operator %q, use !=", op)
+			}
+			words = append(words, op)
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return words, nil
+}
+
+// validate checks that the condition names a known field, with an operator
+// and value that suit it, and normalizes its field and value.
+func (c *pokedexCondition) validate() error {
+	c.field = strings.ToLower(c.field)
+	if alias, ok := pokedexFieldAliases[c.field]; ok {
+		c.field = alias
+	}
+	if !isPokedexOperator(c.op) {
+		return fmt.Errorf("expected an operator after %s, got %q", c.field, c.op)
+	}
+
+	if _, ok := numericPokedexFields[c.field]; ok {
+		if c.field == "generation" {
+			n, err := generationNumber(c.value)
+			if err != nil {
+				return err
+			}
+			c.value = strconv.Itoa(n)
+		}
+		if _, err := strconv.Atoi(c.value); err != nil {
+			return fmt.Errorf("%s needs a number, got %q", c.field, c.value)
+		}
+		return nil
+	}
+
+	if _, ok := textPokedexFields[c.field]; ok {
+		if c.op != "=" && c.op != "!=" {
+			return fmt.Errorf("%s can only be compared with = or !=", c.field)
+		}
+		c.value = apiName(c.value)
+		return nil
+	}
+
+	return fmt.Errorf("unknown field %q", c.field)
+}
+
+func isPokedexOperator(op string) bool {
+	switch op {
+	case "=", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+func (q pokedexQuery) matches(pokemon ownedPokemon) (bool, error) {
+	if len(q) == 0 {
+		return true, nil
+	}
+	for _, group := range q {
+		all := true
+		for _, condition := range group {
+			ok, err := condition.matches(pokemon)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c pokedexCondition) matches(pokemon ownedPokemon) (bool, error) {
+	if value, ok := numericPokedexFields[c.field]; ok {
+		want, err := strconv.Atoi(c.value)
+		if err != nil {
+			return false, err
+		}
+		got := value(pokemon)
+		switch c.op {
+		case "=":
+			return got == want, nil
+		case "!=":
+			return got != want, nil
+		case "<":
+			return got < want, nil
+		case "<=":
+			return got <= want, nil
+		case ">":
+			return got > want, nil
+		case ">=":
+			return got >= want, nil
+		}
+	}
+
+	if values, ok := textPokedexFields[c.field]; ok {
+		has := false
+		for _, value := range values(pokemon) {
+			if apiName(value) == c.value {
+				has = true
+			}
+		}
+		return has == (c.op == "="), nil
+	}
+
+	return false, fmt.Errorf("unknown field %q", c.field)
+}
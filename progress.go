@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dexEntry is a species of a Pokedex, numbered as in the national Pokedex.
+type dexEntry struct {
+	Number  int    `json:"number"`
+	Species string `json:"species"`
+	Seen    bool   `json:"seen"`
+}
+
+type pokedexProgress struct {
+	Pokedex string `json:"pokedex"`
+	Caught  int    `json:"caught"`
+	// Seen counts the entries seen, caught or not.
+	Seen    int        `json:"seen"`
+	Total   int        `json:"total"`
+	Percent int        `json:"percent"`
+	Missing []dexEntry `json:"missing"`
+}
+
+// newPokedexProgress counts which entries of a Pokedex have been seen and
+// caught, and lists the uncaught ones in national Pokedex order.
+func newPokedexProgress(cfg *config, name string, entries []dexEntry) pokedexProgress {
+	caught := caughtSpecies(cfg)
+	progress := pokedexProgress{Pokedex: name, Total: len(entries), Missing: []dexEntry{}}
+	for _, entry := range entries {
+		if caught[entry.Species] {
+			progress.Caught++
+			progress.Seen++
+			continue
+		}
+		if cfg.seenPokemon[entry.Species] {
+			entry.Seen = true
+			progress.Seen++
+		}
+		progress.Missing = append(progress.Missing, entry)
+	}
+	if progress.Total > 0 {
+		progress.Percent = progress.Caught * 100 / progress.Total
+	}
+
+	sort.Slice(progress.Missing, func(i, j int) bool {
+		return progress.Missing[i].Number < progress.Missing[j].Number
+	})
+	return progress
+}
+
+// caughtSpecies returns the species of the caught Pokemon, so that forms
+// such as deoxys-attack count for their species.
+func caughtSpecies(cfg *config) map[string]bool {
+	species := map[string]bool{}
+	for name, caught := range cfg.caughtPokemon {
+		if caught.Species.Name != "" {
+			name = caught.Species.Name
+		}
+		species[name] = true
+	}
+	return species
+}
+
+func (r pokedexProgress) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s: %d/%d caught, %d%%, %d seen\n", r.Pokedex, r.Caught, r.Total, r.Percent, r.Seen)
+	if len(r.Missing) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Missing:")
+	for _, entry := range r.Missing {
+		if entry.Seen {
+			fmt.Fprintf(w, "  #%03d %s (seen)\n", entry.Number, entry.Species)
+		} else {
+			fmt.Fprintf(w, "  #%03d %s\n", entry.Number, entry.Species)
+		}
+	}
+}
+
+func (r pokedexProgress) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, entry := range r.Missing {
+		rows = append(rows, []string{strconv.Itoa(entry.Number), entry.Species, strconv.FormatBool(entry.Seen)})
+	}
+	return []string{"number", "missing", "seen"}, rows
+}
+
+// regionProgress tracks completion of the main Pokedex of a region, the
+// first one PokeAPI lists for it.
+func regionProgress(cfg *config, regionName string) (commandResult, error) {
+	region, err := getRegion(cfg, regionName)
+	if errors.Is(err, errNotFound) {
+		return nil, notFoundError(cfg, "region", regionName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(region.Pokedexes) == 0 {
+		return nil, fmt.Errorf("%s has no regional Pokedex", region.Name)
+	}
+
+	pokedex, err := getPokedex(cfg, region.Pokedexes[0].Name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []dexEntry{}
+	for _, entry := range pokedex.PokemonEntries {
+		entries = append(entries, dexEntry{
+			Number:  nationalNumber(entry.PokemonSpecies),
+			Species: entry.PokemonSpecies.Name,
+		})
+	}
+	return newPokedexProgress(cfg, region.Name, entries), nil
+}
+
+// generationProgress tracks completion of the species a generation
+// introduced.
+func generationProgress(cfg *config, generation string) (commandResult, error) {
+	name, err := generationName(generation)
+	if err != nil {
+		return nil, err
+	}
+
+	gen, err := getGeneration(cfg, name)
+	if errors.Is(err, errNotFound) {
+		return nil, notFoundError(cfg, "generation", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []dexEntry{}
+	for _, species := range gen.PokemonSpecies {
+		entries = append(entries, dexEntry{Number: nationalNumber(species), Species: species.Name})
+	}
+	return newPokedexProgress(cfg, gen.Name, entries), nil
+}
+
+// generationName turns a generation given as a number, a roman numeral or its
+// PokeAPI name into the PokeAPI name, e.g. 3, iii and generation-iii.
+func generationName(generation string) (string, error) {
+	generation = strings.TrimPrefix(apiName(generation), "generation-")
+
+	if n, err := strconv.Atoi(generation); err == nil {
+		if n < 1 || n > len(generationNumerals) {
+			return "", fmt.Errorf("there is no generation %d", n)
+		}
+		return "generation-" + generationNumerals[n-1], nil
+	}
+	return "generation-" + generation, nil
+}
+
+// generationNumerals are the roman numerals PokeAPI names generations with.
+var generationNumerals = []string{"i", "ii", "iii", "iv", "v", "vi", "vii", "viii", "ix"}
+
+// generationNumber is the number of a generation given in any of the forms
+// generationName accepts.
+func generationNumber(generation string) (int, error) {
+	name, err := generationName(generation)
+	if err != nil {
+		return 0, err
+	}
+	for i, numeral := range generationNumerals {
+		if name == "generation-"+numeral {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown generation %q", generation)
+}
+
+// generationLastSpecies holds the national Pokedex number of the last species
+// each generation introduced.
+var generationLastSpecies = []int{151, 251, 386, 493, 649, 721, 809, 905, 1025}
+
+// speciesGeneration is the generation that introduced the species with the
+// given national Pokedex number, or 0 when it is unknown.
+func speciesGeneration(number int) int {
+	if number < 1 {
+		return 0
+	}
+	for i, last := range generationLastSpecies {
+		if number <= last {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// nationalNumber is the national Pokedex number of a species, which is its
+// PokeAPI id, taken from its URL.
+func nationalNumber(species namedAPIResource) int {
+	n, err := strconv.Atoi(path.Base(strings.TrimSuffix(species.URL, "/")))
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/see-why/Pokedex/internal/ansi"
+)
+
+// promptState is the data available to the prompt template, e.g.
+// `[{{.Area}} | {{.Caught}} caught] > `.
+type promptState struct {
+	Region   string
+	Location string
+	Area     string
+	Caught   int
+	Seen     int
+	Balls    int
+	Profile  string
+	// Pending is the number of PokeAPI requests in progress, such as the
+	// name indexes tab completion loads in the background.
+	Pending int
+}
+
+func newPromptState(cfg *config) promptState {
+	return promptState{
+		Region:   cfg.currentRegion,
+		Location: cfg.currentLocation,
+		Area:     cfg.currentArea,
+		Caught:   len(cfg.caughtPokemon),
+		Seen:     len(cfg.seenPokemon),
+		Balls:    cfg.ballsThrown,
+		Profile:  cfg.currentSettings().Profile,
+		Pending:  int(cfg.pendingRequests.Load()),
+	}
+}
+
+// parsePromptTemplate parses a prompt template. The color function styles
+// its argument with space-separated ansi styles, e.g.
+// {{color "bold cyan" .Area}}, and leaves it plain when useColor is false.
+func parsePromptTemplate(text string, useColor bool) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"color": func(styles string, value any) (string, error) {
+			names := strings.Fields(styles)
+			for _, name := range names {
+				if !ansi.Valid(name) {
+					return "", fmt.Errorf("unknown color %q", name)
+				}
+			}
+			if !useColor {
+				return fmt.Sprint(value), nil
+			}
+			return ansi.Paint(fmt.Sprint(value), names...)
+		},
+	}
+	return template.New("prompt").Funcs(funcs).Parse(text)
+}
+
+// validatePromptTemplate checks that text parses and renders, so mistakes
+// are reported when the settings load rather than at every prompt.
+func validatePromptTemplate(text string) error {
+	tmpl, err := parsePromptTemplate(text, false)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(&strings.Builder{}, promptState{})
+}
+
+// renderPrompt renders the prompt template for the current state. A template
+// that fails to render is shown as written.
+func renderPrompt(cfg *config) string {
+	text := cfg.currentSettings().Prompt
+	tmpl, err := parsePromptTemplate(text, cfg.useColor())
+	if err != nil {
+		return text
+	}
+
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, newPromptState(cfg)); err != nil {
+		return text
+	}
+	return prompt.String()
+}
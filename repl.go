@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chzyer/readline"
+)
+
+// Exit statuses for non-interactive runs.
+const (
+	exitOK      = 0
+	exitFailure = 1 // a command returned an error
+	exitUsage   = 2 // an unknown command or a script that could not be read
+)
+
+var errUnknownCommand = errors.New("unknown command")
+
+// startRepl runs the interactive prompt until the user exits.
+func startRepl(cfg *config) {
+	var err error
+
+	// Load the command history saved by earlier sessions
+	s := cfg.currentSettings()
+	historyPath := ""
+	if dir := s.profileDir(); dir != "" {
+		historyPath = filepath.Join(dir, "history")
+	}
+	cfg.history, err = loadHistory(historyPath, s.HistorySize)
+	if err != nil {
+		fmt.Fprintf(cfg.diagWriter(), "Error loading history: %v\n", err)
+	}
+
+	// Create readline instance with command history and tab completion.
+	// History is saved by replHistory, which de-duplicates it first.
+	completer := newReplCompleter(cfg)
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:                 renderPrompt(cfg),
+		AutoComplete:           completer,
+		HistoryLimit:           s.HistorySize,
+		DisableAutoSaveHistory: true,
+	})
+	if err != nil {
+		fmt.Fprintf(cfg.diagWriter(), "Error creating readline: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	defer rl.Close()
+	syncReadlineHistory(rl, cfg.history)
+
+	// Redraw the prompt when a request starts or finishes while the user is
+	// typing, but never while a command is writing its output
+	prompt := &replPrompt{cfg: cfg, setPrompt: rl.SetPrompt, refresh: rl.Refresh}
+	cfg.onRequestsChanged = prompt.requestsChanged
+	go completer.prefetch()
+	cfg.choose = func(question string, options []string) (int, error) {
+		return readlineChoose(rl, cfg, question, options)
+	}
+
+	for {
+		prompt.edit()
+		input, err := rl.Readline()
+		quit := false
+		prompt.run(func() { quit = handleReplLine(cfg, rl, input, err) })
+		if quit {
+			break
+		}
+	}
+}
+
+// replPrompt keeps prompt redraws, which come from other goroutines as
+// requests start and finish, from interleaving with the commands the REPL
+// runs. Commands and redraws take turns holding mu, so redraws also read
+// config only while no command is changing it.
+type replPrompt struct {
+	cfg       *config
+	setPrompt func(string)
+	refresh   func()
+
+	mu      sync.Mutex
+	editing bool
+}
+
+// edit shows the prompt for the current state, ready for the user to type.
+func (p *replPrompt) edit() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setPrompt(renderPrompt(p.cfg))
+	p.editing = true
+}
+
+// run runs a command, with redraws held off until it finishes.
+func (p *replPrompt) run(command func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.editing = false
+	command()
+}
+
+// requestsChanged redraws the prompt if the user is typing. It is skipped
+// while a command runs, since that includes the command's own requests and
+// the prompt is drawn afresh once it finishes.
+func (p *replPrompt) requestsChanged() {
+	if !p.mu.TryLock() {
+		return
+	}
+	defer p.mu.Unlock()
+	if p.editing {
+		p.setPrompt(renderPrompt(p.cfg))
+		p.refresh()
+	}
+}
+
+// handleReplLine runs a line the user entered, or handles the error reading
+// it, and reports whether the REPL should quit.
+func handleReplLine(cfg *config, rl *readline.Instance, input string, err error) bool {
+	if err != nil {
+		// Handle EOF (Ctrl+C, Ctrl+D) or other errors
+		if err == readline.ErrInterrupt || err == io.EOF {
+			fmt.Fprintln(cfg.outWriter(), "\nClosing the Pokedex... Goodbye!")
+			return true
+		}
+		fmt.Fprintf(cfg.outWriter(), "Error reading input: %v\n", err)
+		return false
+	}
+
+	// Re-run a command from history, e.g. !12 or !!
+	if strings.HasPrefix(strings.TrimSpace(input), "!") {
+		input, err = cfg.history.Expand(input)
+		if err != nil {
+			fmt.Fprintf(cfg.outWriter(), "Error: %v\n", err)
+			return false
+		}
+		fmt.Fprintln(cfg.outWriter(), input)
+	}
+
+	if err := cfg.history.Add(input); err != nil {
+		fmt.Fprintf(cfg.diagWriter(), "Error saving history: %v\n", err)
+	}
+	syncReadlineHistory(rl, cfg.history)
+
+	err = executeInput(cfg, input)
+	if errors.Is(err, errUnknownCommand) {
+		fmt.Fprintln(cfg.outWriter(), "Unknown command")
+	} else if err != nil {
+		fmt.Fprintf(cfg.outWriter(), "Error: %v\n", err)
+	}
+	return false
+}
+
+// readlineChoose lists options, numbered from 1, and reads the user's pick by
+// number or name.
+func readlineChoose(rl *readline.Instance, cfg *config, question string, options []string) (int, error) {
+	fmt.Fprintln(cfg.outWriter(), question)
+	for i, option := range options {
+		fmt.Fprintf(cfg.outWriter(), "  %d) %s\n", i+1, option)
+	}
+
+	rl.SetPrompt(fmt.Sprintf("Choose 1-%d: ", len(options)))
+	answer, err := rl.Readline()
+	if err != nil {
+		return 0, fmt.Errorf("no choice made")
+	}
+	return parseChoice(answer, options)
+}
+
+// parseChoice reads a choice among options, given as its number counting from
+// 1 or as its name.
+func parseChoice(answer string, options []string) (int, error) {
+	answer = strings.TrimSpace(answer)
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return n - 1, nil
+	}
+	for i, option := range options {
+		if strings.EqualFold(option, apiName(answer)) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not one of the choices", answer)
+}
+
+// executeInput runs a single line of input through the command registry.
+// Blank input is a no-op.
+func executeInput(cfg *config, input string) error {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return err
+	}
+	return executeTokens(cfg, tokens)
+}
+
+// executeTokens runs a command that has already been split into words, the
+// first of which names the command.
+func executeTokens(cfg *config, tokens []string) error {
+	return executeTokensDepth(cfg, tokens, 0)
+}
+
+// executeTokensDepth runs tokens, expanding user aliases; depth counts the
+// aliases already expanded to reach them.
+func executeTokensDepth(cfg *config, tokens []string, depth int) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	commandName := strings.ToLower(tokens[0])
+
+	// Look up command in registry, then among the user's aliases
+	command, exists := lookupCommand(commandName)
+	if !exists {
+		expansion, ok := cfg.aliases.Get(commandName)
+		if !ok {
+			return fmt.Errorf("%w %q", errUnknownCommand, commandName)
+		}
+		return executeAlias(cfg, commandName, expansion, tokens[1:], depth)
+	}
+
+	flags, args, err := parseArgs(command, tokens[1:])
+	if err != nil {
+		return err
+	}
+
+	result, err := command.callback(cfg, flags, args...)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	return renderResult(cfg.outWriter(), cfg.outputFormat, result, cfg.useColor())
+}
+
+// executeAlias runs the commands an alias expands to, stopping at the first
+// one that fails.
+func executeAlias(cfg *config, name, expansion string, args []string, depth int) error {
+	if depth >= maxAliasDepth {
+		return fmt.Errorf("alias %q expands too deeply, check it for loops", name)
+	}
+
+	commands, err := expandAlias(expansion, args)
+	if err != nil {
+		return fmt.Errorf("alias %q: %w", name, err)
+	}
+	for _, tokens := range commands {
+		if err := executeTokensDepth(cfg, tokens, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runOneShot(cfg *config, args []string) int {
+	err := executeTokens(cfg, args)
+	return reportResult(cfg, err)
+}
+
+// runScriptFile runs the commands in the file at path, one per line.
+func runScriptFile(cfg *config, path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(cfg.diagWriter(), "Error: %v\n", err)
+		return exitUsage
+	}
+	defer f.Close()
+
+	return runScript(cfg, f)
+}
+
+// runScript runs the commands read from r, one per line, skipping blank lines
+// and lines starting with #. It stops at the first command that fails and
+// returns the process exit status.
+func runScript(cfg *config, r io.Reader) int {
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		err := executeInput(cfg, line)
+		if err != nil {
+			return reportResult(cfg, fmt.Errorf("line %d: %w", lineNumber, err))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(cfg.diagWriter(), "Error reading script: %v\n", err)
+		return exitUsage
+	}
+	return exitOK
+}
+
+// reportResult prints err, if any, to diagnostics and maps it to an exit
+// status.
+func reportResult(cfg *config, err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	fmt.Fprintf(cfg.diagWriter(), "Error: %v\n", err)
+	if errors.Is(err, errUnknownCommand) {
+		return exitUsage
+	}
+	return exitFailure
+}
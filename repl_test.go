@@ -1,76 +1,34 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/see-why/Pokedex/internal/evolution"
 	"github.com/see-why/Pokedex/internal/pokecache"
 )
 
-func TestCleanInput(t *testing.T) {
-	cases := []struct {
-		input    string
-		expected []string
-	}{
-		{
-			input:    "  hello  world  ",
-			expected: []string{"hello", "world"},
-		},
-		{
-			input:    "Charmander Bulbasaur PIKACHU",
-			expected: []string{"charmander", "bulbasaur", "pikachu"},
-		},
-		{
-			input:    "hello world",
-			expected: []string{"hello", "world"},
-		},
-		{
-			input:    "   ",
-			expected: []string{},
-		},
-		{
-			input:    "",
-			expected: []string{},
-		},
-		{
-			input:    "singleword",
-			expected: []string{"singleword"},
-		},
-	}
-
-	for _, c := range cases {
-		actual := cleanInput(c.input)
-
-		// Check the length of the actual slice against the expected slice
-		if len(actual) != len(c.expected) {
-			t.Errorf("cleanInput(%q) returned slice of length %d, expected %d",
-				c.input, len(actual), len(c.expected))
-			continue
-		}
-
-		// Check each word in the slice
-		for i := range actual {
-			word := actual[i]
-			expectedWord := c.expected[i]
-			if word != expectedWord {
-				t.Errorf("cleanInput(%q) returned %q at index %d, expected %q",
-					c.input, word, i, expectedWord)
-			}
-		}
-	}
-}
-
 func TestGetCommands(t *testing.T) {
 	commands := getCommands()
 
 	// Test that we have exactly the expected number of commands
-	expectedCount := 8
+	expectedCount := 31
 	if len(commands) != expectedCount {
 		t.Errorf("Expected %d commands, got %d", expectedCount, len(commands))
 	}
 
 	// Test that all expected commands are present
-	expectedCommands := []string{"help", "exit", "map", "mapb", "explore", "catch", "inspect", "pokedex"}
+	expectedCommands := []string{"help", "exit", "map", "mapb", "regions", "region", "locations", "areas", "explore", "catch", "inspect", "species", "evolutions", "evolve", "train", "give", "types", "matchup", "compare", "moves", "move", "ability", "items", "item", "berries", "berry", "pokedex", "history", "alias", "config", "search"}
 
 	for _, expectedCmd := range expectedCommands {
 		_, exists := commands[expectedCmd]
@@ -128,7 +86,7 @@ func TestCommandMapb_FirstPage(t *testing.T) {
 	}
 
 	// This should not return an error and should print "you're on the first page"
-	err := commandMapb(cfg)
+	_, err := commandMapb(cfg, nil)
 	if err != nil {
 		t.Errorf("commandMapb returned unexpected error: %v", err)
 	}
@@ -173,8 +131,8 @@ func TestConfig(t *testing.T) {
 
 func TestCliCommandStruct(t *testing.T) {
 	// Test that the cliCommand struct works as expected
-	testCallback := func(cfg *config, args ...string) error {
-		return nil
+	testCallback := func(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+		return nil, nil
 	}
 
 	cmd := cliCommand{
@@ -199,7 +157,7 @@ func TestCliCommandStruct(t *testing.T) {
 	cfg := &config{
 		pokeapiClient: pokecache.NewCache(5 * time.Minute),
 	}
-	err := cmd.callback(cfg)
+	_, err := cmd.callback(cfg, nil)
 	if err != nil {
 		t.Errorf("callback returned unexpected error: %v", err)
 	}
@@ -210,7 +168,7 @@ func TestCommandExplore_NoArgs(t *testing.T) {
 		pokeapiClient: pokecache.NewCache(5 * time.Minute),
 	}
 
-	err := commandExplore(cfg)
+	_, err := commandExplore(cfg, nil)
 	if err == nil {
 		t.Error("expected error when no arguments provided")
 	}
@@ -224,10 +182,10 @@ func TestCommandExplore_NoArgs(t *testing.T) {
 func TestCommandCatch_NoArgs(t *testing.T) {
 	cfg := &config{
 		pokeapiClient: pokecache.NewCache(5 * time.Minute),
-		caughtPokemon: make(map[string]Pokemon),
+		caughtPokemon: make(map[string]ownedPokemon),
 	}
 
-	err := commandCatch(cfg)
+	_, err := commandCatch(cfg, nil)
 	if err == nil {
 		t.Error("expected error when no arguments provided")
 	}
@@ -241,10 +199,10 @@ func TestCommandCatch_NoArgs(t *testing.T) {
 func TestCommandInspect_NoArgs(t *testing.T) {
 	cfg := &config{
 		pokeapiClient: pokecache.NewCache(5 * time.Minute),
-		caughtPokemon: make(map[string]Pokemon),
+		caughtPokemon: make(map[string]ownedPokemon),
 	}
 
-	err := commandInspect(cfg)
+	_, err := commandInspect(cfg, nil)
 	if err == nil {
 		t.Error("expected error when no arguments provided")
 	}
@@ -258,11 +216,11 @@ func TestCommandInspect_NoArgs(t *testing.T) {
 func TestCommandPokedex_EmptyPokedex(t *testing.T) {
 	cfg := &config{
 		pokeapiClient: pokecache.NewCache(5 * time.Minute),
-		caughtPokemon: make(map[string]Pokemon),
+		caughtPokemon: make(map[string]ownedPokemon),
 	}
 
 	// Should not return an error even with no arguments
-	err := commandPokedex(cfg)
+	_, err := commandPokedex(cfg, nil)
 	if err != nil {
 		t.Errorf("expected no error, got %q", err.Error())
 	}
@@ -271,16 +229,1570 @@ func TestCommandPokedex_EmptyPokedex(t *testing.T) {
 func TestCommandPokedex_WithPokemon(t *testing.T) {
 	cfg := &config{
 		pokeapiClient: pokecache.NewCache(5 * time.Minute),
-		caughtPokemon: make(map[string]Pokemon),
+		caughtPokemon: make(map[string]ownedPokemon),
 	}
 
 	// Add some test Pokemon to the caught list
-	cfg.caughtPokemon["pidgey"] = Pokemon{Name: "pidgey"}
-	cfg.caughtPokemon["caterpie"] = Pokemon{Name: "caterpie"}
+	cfg.caughtPokemon["pidgey"] = ownedPokemon{Pokemon: Pokemon{Name: "pidgey"}}
+	cfg.caughtPokemon["caterpie"] = ownedPokemon{Pokemon: Pokemon{Name: "caterpie"}, Nickname: "Wiggles"}
 
 	// Should not return an error
-	err := commandPokedex(cfg)
+	_, err := commandPokedex(cfg, nil)
 	if err != nil {
 		t.Errorf("expected no error, got %q", err.Error())
 	}
 }
+
+func TestParsePokedexQuery(t *testing.T) {
+	query, err := parsePokedexQuery("Type=Fire and speed>=90 or gen = ii")
+	if err != nil {
+		t.Fatalf("parsePokedexQuery returned unexpected error: %v", err)
+	}
+	expected := pokedexQuery{
+		{{field: "type", op: "=", value: "fire"}, {field: "speed", op: ">=", value: "90"}},
+		{{field: "generation", op: "=", value: "2"}},
+	}
+	if !reflect.DeepEqual(query, expected) {
+		t.Errorf("parsePokedexQuery = %v, expected %v", query, expected)
+	}
+
+	errorCases := map[string]string{
+		"type=fire and":   "incomplete condition at the end of the query",
+		"type>fire":       "type can only be compared with = or !=",
+		"speed>fast":      `speed needs a number, got "fast"`,
+		"colour=red":      `unknown field "colour"`,
+		"speed!90":        `unknown operator "!", use !=`,
+		"type=fire but x": `expected and or or, got "but"`,
+	}
+	for text, expected := range errorCases {
+		_, err := parsePokedexQuery(text)
+		if err == nil || err.Error() != expected {
+			t.Errorf("parsePokedexQuery(%q) error = %v, expected %q", text, err, expected)
+		}
+	}
+}
+
+func TestPokedexFilters_NormalizeFlags(t *testing.T) {
+	query, err := pokedexFilters(commandFlags{"type": "Electric", "gen": "IV", "nickname": "Mr Mime"}, nil)
+	if err != nil {
+		t.Fatalf("pokedexFilters returned unexpected error: %v", err)
+	}
+	expected := pokedexQuery{{
+		{field: "type", op: "=", value: "electric"},
+		{field: "generation", op: "=", value: "4"},
+		{field: "nickname", op: "=", value: "mr-mime"},
+	}}
+	if !reflect.DeepEqual(query, expected) {
+		t.Errorf("pokedexFilters = %v, expected %v", query, expected)
+	}
+
+	_, err = pokedexFilters(commandFlags{"gen": "12"}, nil)
+	if err == nil || err.Error() != "--gen: there is no generation 12" {
+		t.Errorf("expected an error for an unknown generation, got %v", err)
+	}
+}
+
+func TestCommandTypes_RepeatedType(t *testing.T) {
+	cfg, _, _ := newTestConfig()
+
+	_, err := commandTypes(cfg, nil, "fire", "Fire")
+	expected := "a Pokemon cannot have fire as both of its types"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestCommandRegion_SetsCurrentRegion(t *testing.T) {
+	cfg := &config{
+		pokeapiClient:   pokecache.NewCache(5 * time.Minute),
+		currentLocation: "old-location",
+		currentArea:     "old-area",
+	}
+	cfg.pokeapiClient.Add(pokeapiBaseURL+"/region/kanto",
+		[]byte(`{"id":1,"name":"kanto","locations":[{"name":"pallet-town","url":""}]}`))
+
+	_, err := commandRegion(cfg, nil, "kanto")
+	if err != nil {
+		t.Fatalf("commandRegion returned unexpected error: %v", err)
+	}
+
+	if cfg.currentRegion != "kanto" {
+		t.Errorf("currentRegion = %q, expected %q", cfg.currentRegion, "kanto")
+	}
+	if cfg.currentLocation != "" || cfg.currentArea != "" {
+		t.Errorf("expected location and area to be reset, got %q and %q", cfg.currentLocation, cfg.currentArea)
+	}
+}
+
+func TestCommandAreas_NewLocationClearsCurrentArea(t *testing.T) {
+	cfg := &config{
+		pokeapiClient:   pokecache.NewCache(5 * time.Minute),
+		currentLocation: "pallet-town",
+		currentArea:     "pallet-town-area",
+	}
+	cfg.pokeapiClient.Add(pokeapiBaseURL+"/location/viridian-forest",
+		[]byte(`{"id":2,"name":"viridian-forest","region":{"name":"kanto","url":""},"areas":[{"name":"viridian-forest-area","url":""},{"name":"viridian-forest-clearing","url":""}]}`))
+
+	if _, err := commandAreas(cfg, nil, "viridian-forest"); err != nil {
+		t.Fatalf("commandAreas returned unexpected error: %v", err)
+	}
+	if cfg.currentLocation != "viridian-forest" || cfg.currentArea != "" {
+		t.Errorf("expected viridian-forest with no area chosen, got %q and %q", cfg.currentLocation, cfg.currentArea)
+	}
+}
+
+func TestCommandLocations_NoRegion(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+	}
+
+	_, err := commandLocations(cfg, nil)
+	if err == nil {
+		t.Error("expected error when no region is selected")
+	}
+}
+
+func TestCommandAreas_SingleAreaSetsCurrentArea(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+	}
+	cfg.pokeapiClient.Add(pokeapiBaseURL+"/location/pallet-town",
+		[]byte(`{"id":1,"name":"pallet-town","region":{"name":"kanto","url":""},"areas":[{"name":"pallet-town-area","url":""}]}`))
+
+	_, err := commandAreas(cfg, nil, "pallet-town")
+	if err != nil {
+		t.Fatalf("commandAreas returned unexpected error: %v", err)
+	}
+
+	if cfg.currentRegion != "kanto" {
+		t.Errorf("currentRegion = %q, expected %q", cfg.currentRegion, "kanto")
+	}
+	if cfg.currentLocation != "pallet-town" {
+		t.Errorf("currentLocation = %q, expected %q", cfg.currentLocation, "pallet-town")
+	}
+	if cfg.currentArea != "pallet-town-area" {
+		t.Errorf("currentArea = %q, expected %q", cfg.currentArea, "pallet-town-area")
+	}
+}
+
+func TestCommandExplore_UsesCurrentArea(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+		currentArea:   "pallet-town-area",
+	}
+	cfg.pokeapiClient.Add(pokeapiBaseURL+"/location-area/pallet-town-area",
+		[]byte(`{"id":1,"name":"pallet-town-area","pokemon_encounters":[{"pokemon":{"name":"pidgey","url":""}}]}`))
+
+	_, err := commandExplore(cfg, nil)
+	if err != nil {
+		t.Errorf("commandExplore returned unexpected error: %v", err)
+	}
+}
+
+func TestCommandExplore_MovesToTheAreaLocation(t *testing.T) {
+	cfg, _, _ := newTestConfig()
+	cfg.currentRegion = "johto"
+	cfg.currentLocation = "new-bark-town"
+	cfg.currentArea = "new-bark-town-area"
+
+	if _, err := commandExplore(cfg, nil, "pallet-town-area"); err != nil {
+		t.Fatalf("commandExplore returned unexpected error: %v", err)
+	}
+	if cfg.currentRegion != "kanto" || cfg.currentLocation != "pallet-town" || cfg.currentArea != "pallet-town-area" {
+		t.Errorf("expected kanto, pallet-town and pallet-town-area, got %q, %q and %q", cfg.currentRegion, cfg.currentLocation, cfg.currentArea)
+	}
+}
+
+func TestMapPageURL(t *testing.T) {
+	cases := []struct {
+		name     string
+		next     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "page uses default limit",
+			next:     pokeapiBaseURL + "/location-area",
+			args:     []string{"--page", "3"},
+			expected: pokeapiBaseURL + "/location-area?limit=20&offset=40",
+		},
+		{
+			name:     "page and limit",
+			next:     pokeapiBaseURL + "/location-area",
+			args:     []string{"--page=2", "--limit=50"},
+			expected: pokeapiBaseURL + "/location-area?limit=50&offset=50",
+		},
+		{
+			name:     "limit keeps current offset",
+			next:     pokeapiBaseURL + "/location-area?offset=60&limit=20",
+			args:     []string{"--limit", "10"},
+			expected: pokeapiBaseURL + "/location-area?limit=10&offset=60",
+		},
+		{
+			name:     "offset",
+			next:     pokeapiBaseURL + "/location-area",
+			args:     []string{"--offset", "7"},
+			expected: pokeapiBaseURL + "/location-area?limit=20&offset=7",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := &config{nextLocationURL: c.next}
+			flags, _, err := parseArgs(getCommands()["map"], c.args)
+			if err != nil {
+				t.Fatalf("parseArgs returned unexpected error: %v", err)
+			}
+			actual, err := mapPageURL(cfg, flags)
+			if err != nil {
+				t.Fatalf("mapPageURL returned unexpected error: %v", err)
+			}
+			if actual != c.expected {
+				t.Errorf("mapPageURL(%q) = %q, expected %q", c.args, actual, c.expected)
+			}
+		})
+	}
+}
+
+func TestMapPageURL_InvalidFlags(t *testing.T) {
+	cases := [][]string{
+		{"--page"},
+		{"--page", "zero"},
+		{"--page", "0"},
+		{"--limit", "-1"},
+		{"--page", "2", "--offset", "10"},
+		{"--unknown", "1"},
+	}
+
+	for _, args := range cases {
+		cfg := &config{nextLocationURL: pokeapiBaseURL + "/location-area"}
+		flags, _, err := parseArgs(getCommands()["map"], args)
+		if err == nil {
+			_, err = mapPageURL(cfg, flags)
+		}
+		if err == nil {
+			t.Errorf("mapPageURL(%q) expected an error", args)
+		}
+	}
+}
+
+func TestCommandMap_PageUpdatesURLs(t *testing.T) {
+	cfg := &config{
+		pokeapiClient:   pokecache.NewCache(5 * time.Minute),
+		nextLocationURL: pokeapiBaseURL + "/location-area",
+	}
+	pageURL := pokeapiBaseURL + "/location-area?limit=20&offset=40"
+	cfg.pokeapiClient.Add(pageURL, []byte(`{
+		"count": 1089,
+		"next": "https://pokeapi.co/api/v2/location-area?offset=60&limit=20",
+		"previous": "https://pokeapi.co/api/v2/location-area?offset=20&limit=20",
+		"results": [{"name": "mt-coronet-1f-route-216", "url": ""}]
+	}`))
+
+	_, err := commandMap(cfg, commandFlags{"page": "3"})
+	if err != nil {
+		t.Fatalf("commandMap returned unexpected error: %v", err)
+	}
+
+	if cfg.nextLocationURL != "https://pokeapi.co/api/v2/location-area?offset=60&limit=20" {
+		t.Errorf("nextLocationURL = %q", cfg.nextLocationURL)
+	}
+	if cfg.previousLocationURL == nil || *cfg.previousLocationURL != "https://pokeapi.co/api/v2/location-area?offset=20&limit=20" {
+		t.Errorf("previousLocationURL = %v", cfg.previousLocationURL)
+	}
+}
+
+func TestFetchResource_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+	}
+
+	_, err := fetchResource[Pokemon](cfg, server.URL+"/pokemon/charmnder")
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("expected errNotFound, got %v", err)
+	}
+
+	if _, ok := cfg.pokeapiClient.Get(server.URL + "/pokemon/charmnder"); ok {
+		t.Error("expected a not found response to not be cached")
+	}
+}
+
+func TestNotFoundError_Suggestions(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+	}
+	cfg.pokeapiClient.Add(fmt.Sprintf("%s/pokemon?limit=%d", pokeapiBaseURL, nameIndexLimit),
+		[]byte(`{"count":3,"results":[{"name":"charmander"},{"name":"charmeleon"},{"name":"pikachu"}]}`))
+
+	err := notFoundError(cfg, "pokemon", "charmnder")
+	expectedError := `pokemon "charmnder" not found, did you mean charmander?`
+	if err.Error() != expectedError {
+		t.Errorf("expected error %q, got %q", expectedError, err.Error())
+	}
+
+	err = notFoundError(cfg, "pokemon", "mewthree")
+	expectedError = `pokemon "mewthree" not found`
+	if err.Error() != expectedError {
+		t.Errorf("expected error %q, got %q", expectedError, err.Error())
+	}
+}
+
+func TestCommandSearch(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+	}
+	cfg.pokeapiClient.Add(fmt.Sprintf("%s/pokemon?limit=%d", pokeapiBaseURL, nameIndexLimit),
+		[]byte(`{"count":2,"results":[{"name":"pikachu"},{"name":"raichu"}]}`))
+	cfg.pokeapiClient.Add(fmt.Sprintf("%s/location-area?limit=%d", pokeapiBaseURL, nameIndexLimit),
+		[]byte(`{"count":1,"results":[{"name":"pallet-town-area"}]}`))
+
+	if _, err := commandSearch(cfg, nil, "chu"); err != nil {
+		t.Errorf("commandSearch returned unexpected error: %v", err)
+	}
+
+	_, err := commandSearch(cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "search term") {
+		t.Errorf("expected missing search term error, got %v", err)
+	}
+}
+
+func TestReplCompleter(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+		caughtPokemon: map[string]ownedPokemon{
+			"pidgey":   {Pokemon: Pokemon{Name: "pidgey"}},
+			"caterpie": {Pokemon: Pokemon{Name: "caterpie"}, Nickname: "Wiggles"},
+			"mr-mime":  {Pokemon: Pokemon{Name: "mr-mime"}, Nickname: "Mr Mime"},
+		},
+	}
+	cfg.pokeapiClient.Add(fmt.Sprintf("%s/pokemon?limit=%d", pokeapiBaseURL, nameIndexLimit),
+		[]byte(`{"count":3,"results":[{"name":"pikachu"},{"name":"pidgey"},{"name":"raichu"}]}`))
+	cfg.pokeapiClient.Add(fmt.Sprintf("%s/location-area?limit=%d", pokeapiBaseURL, nameIndexLimit),
+		[]byte(`{"count":2,"results":[{"name":"pallet-town-area"},{"name":"viridian-forest-area"}]}`))
+
+	cases := []struct {
+		line     string
+		expected []string
+		length   int
+	}{
+		{line: "insp", expected: []string{"ect "}, length: 4},
+		{line: "catch pi", expected: []string{"kachu ", "dgey "}, length: 2},
+		{line: "CATCH Rai", expected: []string{"chu "}, length: 3},
+		{line: "c pi", expected: []string{"kachu ", "dgey "}, length: 2},
+		{line: "explore v", expected: []string{"iridian-forest-area "}, length: 1},
+		{line: "inspect ", expected: []string{"Mr\\ Mime ", "Wiggles ", "caterpie ", "mr-mime ", "pidgey "}, length: 0},
+		{line: "inspect wig", expected: []string{"gles "}, length: 3},
+		{line: "catch pikachu ", expected: []string{}, length: 0},
+		{line: "pokedex ", expected: []string{}, length: 0},
+		{line: `inspect "Wig`, expected: []string{`gles" `}, length: 3},
+		{line: "inspect 'mr Mi", expected: []string{"me' "}, length: 5},
+		{line: `inspect mr\ m`, expected: []string{"ime "}, length: 4},
+		{line: "inspect mr ", expected: []string{}, length: 0},
+		{line: "inspect \"Wiggles\" ", expected: []string{}, length: 0},
+	}
+
+	completer := newReplCompleter(cfg)
+	for _, c := range cases {
+		suffixes, length := completer.Do([]rune(c.line), len([]rune(c.line)))
+
+		if length != c.length {
+			t.Errorf("Do(%q) returned length %d, expected %d", c.line, length, c.length)
+		}
+		if len(suffixes) != len(c.expected) {
+			t.Errorf("Do(%q) returned %d suggestions, expected %d", c.line, len(suffixes), len(c.expected))
+			continue
+		}
+		for i := range suffixes {
+			if string(suffixes[i]) != c.expected[i] {
+				t.Errorf("Do(%q) returned %q at index %d, expected %q", c.line, string(suffixes[i]), i, c.expected[i])
+			}
+		}
+	}
+}
+
+func TestReplHistory_DeduplicatesAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedex", "history")
+
+	history, err := loadHistory(path, 3)
+	if err != nil {
+		t.Fatalf("loadHistory returned unexpected error: %v", err)
+	}
+
+	for _, line := range []string{"map", "explore pallet-town-area", "map", "catch pikachu", "  ", "pokedex"} {
+		if err := history.Add(line); err != nil {
+			t.Fatalf("Add(%q) returned unexpected error: %v", line, err)
+		}
+	}
+
+	expected := []string{"map", "catch pikachu", "pokedex"}
+	reloaded, err := loadHistory(path, 3)
+	if err != nil {
+		t.Fatalf("loadHistory returned unexpected error: %v", err)
+	}
+	for _, h := range []*replHistory{history, reloaded} {
+		entries := h.Entries()
+		if len(entries) != len(expected) {
+			t.Fatalf("history has %d entries, expected %d: %q", len(entries), len(expected), entries)
+		}
+		for i := range entries {
+			if entries[i] != expected[i] {
+				t.Errorf("history entry %d = %q, expected %q", i, entries[i], expected[i])
+			}
+		}
+	}
+}
+
+func TestReplHistory_Expand(t *testing.T) {
+	history, _ := loadHistory("", 0)
+	history.Add("map")
+	history.Add("catch pikachu")
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "!1", expected: "map"},
+		{input: "!2", expected: "catch pikachu"},
+		{input: "!!", expected: "catch pikachu"},
+	}
+	for _, c := range cases {
+		actual, err := history.Expand(c.input)
+		if err != nil {
+			t.Errorf("Expand(%q) returned unexpected error: %v", c.input, err)
+		}
+		if actual != c.expected {
+			t.Errorf("Expand(%q) = %q, expected %q", c.input, actual, c.expected)
+		}
+	}
+
+	for _, input := range []string{"!0", "!3", "!abc"} {
+		if _, err := history.Expand(input); err == nil {
+			t.Errorf("Expand(%q) expected an error", input)
+		}
+	}
+}
+
+func TestCommandHistory_NoHistory(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+	}
+
+	if _, err := commandHistory(cfg, nil); err != nil {
+		t.Errorf("commandHistory returned unexpected error: %v", err)
+	}
+}
+
+func TestExecuteInput_UnknownCommand(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+	}
+
+	err := executeInput(cfg, "fly cerulean-city")
+	if !errors.Is(err, errUnknownCommand) {
+		t.Errorf("expected errUnknownCommand, got %v", err)
+	}
+
+	if err := executeInput(cfg, "   "); err != nil {
+		t.Errorf("expected blank input to be a no-op, got %v", err)
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	cases := []struct {
+		name     string
+		script   string
+		expected int
+	}{
+		{
+			name:     "all commands succeed",
+			script:   "# build a team\n\npokedex\n  history  \n",
+			expected: exitOK,
+		},
+		{
+			name:     "command fails",
+			script:   "pokedex\ninspect\npokedex\n",
+			expected: exitFailure,
+		},
+		{
+			name:     "unknown command",
+			script:   "fly cerulean-city\n",
+			expected: exitUsage,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := &config{
+				pokeapiClient: pokecache.NewCache(5 * time.Minute),
+				caughtPokemon: make(map[string]ownedPokemon),
+			}
+
+			actual := runScript(cfg, strings.NewReader(c.script))
+			if actual != c.expected {
+				t.Errorf("runScript returned status %d, expected %d", actual, c.expected)
+			}
+		})
+	}
+}
+
+func TestRunOneShot(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+		caughtPokemon: make(map[string]ownedPokemon),
+	}
+
+	if status := runOneShot(cfg, []string{"pokedex"}); status != exitOK {
+		t.Errorf("runOneShot(pokedex) returned status %d, expected %d", status, exitOK)
+	}
+	if status := runOneShot(cfg, []string{"catch"}); status != exitFailure {
+		t.Errorf("runOneShot(catch) returned status %d, expected %d", status, exitFailure)
+	}
+}
+
+func TestCommandExplore_Result(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+	}
+	cfg.pokeapiClient.Add(pokeapiBaseURL+"/location-area/pallet-town-area",
+		[]byte(`{"id":1,"name":"pallet-town-area","pokemon_encounters":[{"pokemon":{"name":"pidgey","url":""}},{"pokemon":{"name":"rattata","url":""}}]}`))
+
+	result, err := commandExplore(cfg, nil, "pallet-town-area")
+	if err != nil {
+		t.Fatalf("commandExplore returned unexpected error: %v", err)
+	}
+
+	explored, ok := result.(exploreResult)
+	if !ok {
+		t.Fatalf("commandExplore returned %T, expected exploreResult", result)
+	}
+	if explored.Area != "pallet-town-area" || len(explored.Pokemon) != 2 {
+		t.Errorf("unexpected explore result: %+v", explored)
+	}
+}
+
+func TestGetCommands_Metadata(t *testing.T) {
+	validCategories := map[commandCategory]bool{}
+	for _, category := range commandCategories {
+		validCategories[category] = true
+	}
+
+	for name, cmd := range getCommands() {
+		if cmd.name != name {
+			t.Errorf("command registered as %q has name %q", name, cmd.name)
+		}
+		if !validCategories[cmd.category] {
+			t.Errorf("command %q has unknown category %q", name, cmd.category)
+		}
+		if cmd.description == "" {
+			t.Errorf("command %q has no description", name)
+		}
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	commands := getCommands()
+
+	cases := map[string]string{
+		"map":     "map [--page N] [--limit N] [--offset N]",
+		"explore": "explore [location-area]",
+		"catch":   "catch [--nickname NAME] <pokemon>",
+		"pokedex": "pokedex [--region REGION] [--generation N] [--sort KEY] [--reverse] [--type TYPE] [--gen N] [--stat EXPR] [--nickname NAME] [--caught-in AREA] [where] [query...]",
+		"matchup": "matchup <pokemon> [vs] <opponent>",
+		"compare": "compare <pokemon> <other> [more...]",
+	}
+	for name, expected := range cases {
+		actual := commandUsage(commands[name])
+		if actual != expected {
+			t.Errorf("commandUsage(%q) = %q, expected %q", name, actual, expected)
+		}
+	}
+}
+
+func TestCommandHelp_UnknownCommand(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+	}
+
+	_, err := commandHelp(cfg, nil, "explor")
+	expectedError := `unknown command "explor", did you mean explore?`
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("expected error %q, got %v", expectedError, err)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "  catch   pikachu ", expected: []string{"catch", "pikachu"}},
+		{input: `catch pikachu --nickname "Sparky Jr"`, expected: []string{"catch", "pikachu", "--nickname", "Sparky Jr"}},
+		{input: `catch 'mr mime' --nickname='It''s'`, expected: []string{"catch", "mr mime", "--nickname=Its"}},
+		{input: `say "a \"quoted\" \\ word"`, expected: []string{"say", `a "quoted" \ word`}},
+		{input: `Sparky\ Jr ""`, expected: []string{"Sparky Jr", ""}},
+		{input: "", expected: []string{}},
+	}
+
+	for _, c := range cases {
+		actual, err := tokenize(c.input)
+		if err != nil {
+			t.Errorf("tokenize(%q) returned unexpected error: %v", c.input, err)
+			continue
+		}
+		if len(actual) != len(c.expected) {
+			t.Errorf("tokenize(%q) = %q, expected %q", c.input, actual, c.expected)
+			continue
+		}
+		for i := range actual {
+			if actual[i] != c.expected[i] {
+				t.Errorf("tokenize(%q) returned %q at index %d, expected %q", c.input, actual[i], i, c.expected[i])
+			}
+		}
+	}
+
+	for _, input := range []string{`catch "pikachu`, `catch 'pikachu`, `catch pikachu\`} {
+		if _, err := tokenize(input); err == nil {
+			t.Errorf("tokenize(%q) expected an error", input)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	commands := getCommands()
+
+	flags, args, err := parseArgs(commands["catch"], []string{"Mr Mime", "--nickname", "Mister"})
+	if err != nil {
+		t.Fatalf("parseArgs returned unexpected error: %v", err)
+	}
+	if len(args) != 1 || args[0] != "mr-mime" {
+		t.Errorf("expected identifier argument to be normalized to mr-mime, got %q", args)
+	}
+	if flags.get("nickname") != "Mister" {
+		t.Errorf("expected nickname to keep its case, got %q", flags.get("nickname"))
+	}
+
+	_, args, err = parseArgs(commands["inspect"], []string{"Mister"})
+	if err != nil {
+		t.Fatalf("parseArgs returned unexpected error: %v", err)
+	}
+	if args[0] != "Mister" {
+		t.Errorf("expected non-identifier argument to keep its case, got %q", args[0])
+	}
+
+	_, args, err = parseArgs(commands["catch"], []string{"--", "--pikachu"})
+	if err != nil || len(args) != 1 || args[0] != "--pikachu" {
+		t.Errorf("expected -- to end flag parsing, got %q, %v", args, err)
+	}
+
+	_, args, err = parseArgs(commands["pokedex"], []string{"where", "type=fire", "and", "speed", ">", "90"})
+	if err != nil || len(args) != 6 {
+		t.Errorf("expected a variadic argument to take every remaining token, got %q, %v", args, err)
+	}
+
+	errorCases := []struct {
+		command  string
+		tokens   []string
+		expected string
+	}{
+		{command: "catch", tokens: []string{"pikachu", "--shiny"}, expected: "unknown flag --shiny for catch, usage: catch [--nickname NAME] <pokemon>"},
+		{command: "catch", tokens: []string{"pikachu", "--nickname"}, expected: "flag --nickname for catch needs a value"},
+		{command: "history", tokens: []string{"extra"}, expected: "too many arguments for history, usage: history"},
+	}
+	for _, c := range errorCases {
+		_, _, err := parseArgs(commands[c.command], c.tokens)
+		if err == nil || err.Error() != c.expected {
+			t.Errorf("parseArgs(%q, %q) error = %v, expected %q", c.command, c.tokens, err, c.expected)
+		}
+	}
+}
+
+func TestCommandInspect_ByNickname(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+		caughtPokemon: map[string]ownedPokemon{
+			"pikachu": {Pokemon: Pokemon{Name: "pikachu"}, Nickname: "Sparky"},
+		},
+	}
+
+	for _, name := range []string{"Sparky", "sparky", "pikachu", "PIKACHU"} {
+		result, err := commandInspect(cfg, nil, name)
+		if err != nil {
+			t.Fatalf("commandInspect(%q) returned unexpected error: %v", name, err)
+		}
+		inspected, ok := result.(inspectResult)
+		if !ok {
+			t.Errorf("commandInspect(%q) returned %T, expected inspectResult", name, result)
+			continue
+		}
+		if inspected.Nickname != "Sparky" {
+			t.Errorf("commandInspect(%q) nickname = %q, expected %q", name, inspected.Nickname, "Sparky")
+		}
+	}
+}
+
+func TestBuiltInAliases(t *testing.T) {
+	commands := getCommands()
+
+	seen := map[string]string{}
+	for name, cmd := range commands {
+		for _, alias := range cmd.aliases {
+			if _, exists := commands[alias]; exists {
+				t.Errorf("alias %q of %q shadows a command", alias, name)
+			}
+			if other, exists := seen[alias]; exists {
+				t.Errorf("alias %q is used by both %q and %q", alias, other, name)
+			}
+			seen[alias] = name
+		}
+	}
+
+	for alias, expected := range map[string]string{"e": "explore", "c": "catch", "i": "inspect", "ls": "pokedex"} {
+		cmd, ok := lookupCommand(alias)
+		if !ok || cmd.name != expected {
+			t.Errorf("lookupCommand(%q) = %q, %v, expected %q", alias, cmd.name, ok, expected)
+		}
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	cases := []struct {
+		expansion string
+		args      []string
+		expected  [][]string
+	}{
+		{
+			expansion: "map; explore $1; catch $2",
+			args:      []string{"pallet-town-area", "pidgey"},
+			expected:  [][]string{{"map"}, {"explore", "pallet-town-area"}, {"catch", "pidgey"}},
+		},
+		{
+			// A missing argument drops its placeholder so explore uses the current area
+			expansion: "explore $1; catch $2",
+			args:      []string{},
+			expected:  [][]string{{"explore"}, {"catch"}},
+		},
+		{
+			expansion: "catch $1 --nickname=$2",
+			args:      []string{"pikachu", "Sparky Jr"},
+			expected:  [][]string{{"catch", "pikachu", "--nickname=Sparky Jr"}},
+		},
+		{
+			expansion: "search $@",
+			args:      []string{"chu"},
+			expected:  [][]string{{"search", "chu"}},
+		},
+		{
+			// Without placeholders the arguments go to the last command
+			expansion: "pokedex; inspect",
+			args:      []string{"pikachu"},
+			expected:  [][]string{{"pokedex"}, {"inspect", "pikachu"}},
+		},
+		{
+			// Quoted and escaped semicolons do not separate commands
+			expansion: `catch "a;b" --nickname 'x; y'; inspect a\;b`,
+			args:      []string{},
+			expected:  [][]string{{"catch", "a;b", "--nickname", "x; y"}, {"inspect", "a;b"}},
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := expandAlias(c.expansion, c.args)
+		if err != nil {
+			t.Errorf("expandAlias(%q, %q) returned unexpected error: %v", c.expansion, c.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expandAlias(%q, %q) = %q, expected %q", c.expansion, c.args, actual, c.expected)
+		}
+	}
+
+	if _, err := expandAlias(" ; ", nil); err == nil {
+		t.Error("expected an error for an alias that expands to no commands")
+	}
+}
+
+func TestParseAliasDefinition(t *testing.T) {
+	for _, args := range [][]string{
+		{"hunt", "=", "map; explore $1"},
+		{"Hunt", "map; explore $1"},
+		{"hunt=map; explore $1"},
+	} {
+		name, expansion, err := parseAliasDefinition(args)
+		if err != nil {
+			t.Errorf("parseAliasDefinition(%q) returned unexpected error: %v", args, err)
+			continue
+		}
+		if name != "hunt" || expansion != "map; explore $1" {
+			t.Errorf("parseAliasDefinition(%q) = %q, %q", args, name, expansion)
+		}
+	}
+
+	for _, args := range [][]string{{"hunt"}, {"hunt", "="}, {"$hunt", "map"}, {"hunt", "=", ""}} {
+		if _, _, err := parseAliasDefinition(args); err == nil {
+			t.Errorf("parseAliasDefinition(%q) expected an error", args)
+		}
+	}
+}
+
+func TestUserAliases_Persist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "aliases")
+
+	aliases, err := loadAliases(path)
+	if err != nil {
+		t.Fatalf("loadAliases returned unexpected error: %v", err)
+	}
+	if err := aliases.Set("hunt", `map; catch $1 --nickname "Big \\ Guy"`); err != nil {
+		t.Fatalf("Set returned unexpected error: %v", err)
+	}
+	if err := aliases.Set("dex", "pokedex"); err != nil {
+		t.Fatalf("Set returned unexpected error: %v", err)
+	}
+	if err := aliases.Remove("dex"); err != nil {
+		t.Fatalf("Remove returned unexpected error: %v", err)
+	}
+	if err := aliases.Remove("dex"); err == nil {
+		t.Error("expected an error removing a missing alias")
+	}
+
+	reloaded, err := loadAliases(path)
+	if err != nil {
+		t.Fatalf("loadAliases returned unexpected error: %v", err)
+	}
+	expansion, ok := reloaded.Get("hunt")
+	if !ok || expansion != `map; catch $1 --nickname "Big \\ Guy"` {
+		t.Errorf("reloaded alias = %q, %v", expansion, ok)
+	}
+	if names := reloaded.Names(); len(names) != 1 {
+		t.Errorf("expected only the hunt alias after reloading, got %q", names)
+	}
+}
+
+func TestLoadAliases_InvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases")
+	content := "# my aliases\nalias dex = pokedex\nexplore everywhere\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := loadAliases(path)
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected an error for line 3, got %v", err)
+	}
+}
+
+func TestExecuteInput_Aliases(t *testing.T) {
+	cfg, out, _ := newTestConfig()
+	cfg.aliases, _ = loadAliases("")
+
+	if err := executeInput(cfg, "alias dex = pokedex"); err != nil {
+		t.Fatalf("defining an alias returned unexpected error: %v", err)
+	}
+	out.Reset()
+	if err := executeInput(cfg, "DEX"); err != nil {
+		t.Fatalf("running an alias returned unexpected error: %v", err)
+	}
+	if out.String() != "Your Pokedex:\n (No Pokemon caught yet)\n" {
+		t.Errorf("alias output = %q", out.String())
+	}
+
+	if err := executeInput(cfg, "alias map = pokedex"); err == nil {
+		t.Error("expected an error for an alias that shadows a command")
+	}
+	if err := executeInput(cfg, "alias e = pokedex"); err == nil {
+		t.Error("expected an error for an alias that shadows a built-in alias")
+	}
+
+	cfg.aliases.Set("loop", "loop")
+	err := executeInput(cfg, "loop")
+	if err == nil || !strings.Contains(err.Error(), "too deeply") {
+		t.Errorf("expected a loop error, got %v", err)
+	}
+
+	cfg.aliases.Set("bad", "pokedex; nosuchcommand")
+	if err := executeInput(cfg, "bad"); !errors.Is(err, errUnknownCommand) {
+		t.Errorf("expected the unknown command in a macro to be reported, got %v", err)
+	}
+}
+
+func TestLoadSettings_Defaults(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	s, args, err := loadSettings([]string{"catch", "pikachu", "--nickname", "Sparky"}, func(string) string { return "" }, io.Discard)
+	if err != nil {
+		t.Fatalf("loadSettings returned unexpected error: %v", err)
+	}
+
+	if s.CacheInterval != 5*time.Minute || s.APIBaseURL != pokeapiBaseURL || s.Prompt != "Pokedex > " {
+		t.Errorf("unexpected defaults: %+v", s)
+	}
+	if s.CatchBaseChance != 50 || s.CatchMinChance != 5 || s.CatchExpDivisor != 10 {
+		t.Errorf("unexpected catch defaults: %+v", s)
+	}
+	if !s.configFileMissing || len(s.sources) != 0 {
+		t.Errorf("expected a missing config file and no sources, got %v, %v", s.configFileMissing, s.sources)
+	}
+	if fmt.Sprint(args) != "[catch pikachu --nickname Sparky]" {
+		t.Errorf("expected the command to be left in the arguments, got %q", args)
+	}
+}
+
+func TestLoadSettings_Precedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"cache_interval": "10m", "prompt": "> ", "catch_base_chance": 70, "output": "yaml"}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"POKEDEX_CONFIG":         path,
+		"POKEDEX_CACHE_INTERVAL": "1h",
+		"POKEDEX_OUTPUT":         "table",
+	}
+
+	s, _, err := loadSettings([]string{"-o", "json", "--history-size=50"}, func(key string) string { return env[key] }, io.Discard)
+	if err != nil {
+		t.Fatalf("loadSettings returned unexpected error: %v", err)
+	}
+
+	if s.Prompt != "> " || s.CatchBaseChance != 70 {
+		t.Errorf("expected values from the config file, got %q and %d", s.Prompt, s.CatchBaseChance)
+	}
+	if s.CacheInterval != time.Hour {
+		t.Errorf("expected the environment to override the file, got %v", s.CacheInterval)
+	}
+	if s.Output != outputJSON || s.HistorySize != 50 {
+		t.Errorf("expected flags to override everything, got %q and %d", s.Output, s.HistorySize)
+	}
+
+	expectedSources := map[string]string{
+		"cache_interval":    "env POKEDEX_CACHE_INTERVAL",
+		"prompt":            "config file",
+		"catch_base_chance": "config file",
+		"output":            "flag -o",
+		"history_size":      "flag --history-size",
+	}
+	if fmt.Sprint(s.sources) != fmt.Sprint(expectedSources) {
+		t.Errorf("sources = %v, expected %v", s.sources, expectedSources)
+	}
+}
+
+func TestLoadSettings_Errors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cases := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		expected string
+	}{
+		{name: "unknown setting", args: []string{"--config", write("typo.json", `{"promt": "> "}`)}, expected: `unknown setting "promt", did you mean prompt?`},
+		{name: "invalid file", args: []string{"--config", write("broken.json", `{`)}, expected: "broken.json"},
+		{name: "not json", args: []string{"--config", write("config.toml", `prompt = "> "`)}, expected: "config files are JSON, .toml is not supported"},
+		{name: "missing explicit file", args: []string{"--config", filepath.Join(dir, "missing.json")}, expected: "missing.json"},
+		{name: "invalid env", env: map[string]string{"POKEDEX_CATCH_MIN_CHANCE": "0"}, expected: "catch_min_chance from env POKEDEX_CATCH_MIN_CHANCE needs a whole number from 1 to 100"},
+		{name: "invalid flag", args: []string{"--cache-interval", "soon"}, expected: "cache_interval from flag --cache-interval needs a positive duration"},
+		{name: "unknown flag", args: []string{"--verbose"}, expected: "flag provided but not defined"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", dir)
+			_, _, err := loadSettings(c.args, func(key string) string { return c.env[key] }, io.Discard)
+			if err == nil || !strings.Contains(err.Error(), c.expected) {
+				t.Errorf("expected an error containing %q, got %v", c.expected, err)
+			}
+		})
+	}
+}
+
+func TestCommandCatch_UsesSettings(t *testing.T) {
+	cfg, _, _ := newTestConfig()
+	cfg.settings = defaultSettings()
+	cfg.settings.CatchMinChance = 100
+	cfg.rollPercent = func() int { return 100 }
+
+	result, err := commandCatch(cfg, nil, "pikachu")
+	if err != nil {
+		t.Fatalf("commandCatch returned unexpected error: %v", err)
+	}
+	if !result.(catchResult).Caught {
+		t.Error("expected a catch chance of 100 to always catch")
+	}
+	if cfg.ballsThrown != 1 {
+		t.Errorf("expected one ball thrown, got %d", cfg.ballsThrown)
+	}
+}
+
+func TestFetchResource_UsesConfiguredBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/region" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"count": 1, "results": [{"name": "kanto", "url": ""}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+		settings:      defaultSettings(),
+	}
+	cfg.settings.APIBaseURL = server.URL + "/v2"
+
+	result, err := commandRegions(cfg, nil)
+	if err != nil {
+		t.Fatalf("commandRegions returned unexpected error: %v", err)
+	}
+	if fmt.Sprint(result.(regionsResult).Regions) != "[kanto]" {
+		t.Errorf("unexpected regions %v", result)
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	cfg, _, _ := newTestConfig()
+	cfg.settings = defaultSettings()
+	cfg.settings.Color = colorNever
+	cfg.currentArea = "pallet-town-area"
+	cfg.caughtPokemon["pikachu"] = ownedPokemon{Pokemon: Pokemon{Name: "pikachu"}}
+	cfg.ballsThrown = 3
+
+	cases := []struct {
+		template string
+		color    colorMode
+		expected string
+	}{
+		{template: "Pokedex > ", color: colorNever, expected: "Pokedex > "},
+		{template: "[{{.Area}} | {{.Caught}} caught] > ", color: colorNever, expected: "[pallet-town-area | 1 caught] > "},
+		{template: "{{.Profile}} {{.Balls}} balls{{if .Pending}} ...{{end}} > ", color: colorNever, expected: "default 3 balls > "},
+		{template: `{{color "bold cyan" .Area}} > `, color: colorNever, expected: "pallet-town-area > "},
+		{template: `{{color "bold cyan" .Area}} > `, color: colorAlways, expected: "\x1b[1;36mpallet-town-area\x1b[0m > "},
+	}
+
+	for _, c := range cases {
+		cfg.settings.Prompt = c.template
+		cfg.settings.Color = c.color
+		if actual := renderPrompt(cfg); actual != c.expected {
+			t.Errorf("renderPrompt(%q) = %q, expected %q", c.template, actual, c.expected)
+		}
+	}
+}
+
+func TestValidatePromptTemplate(t *testing.T) {
+	for _, template := range []string{"{{.Area", "{{.Trainer}} > ", `{{color "purple" .Area}}`} {
+		if err := validatePromptTemplate(template); err == nil {
+			t.Errorf("validatePromptTemplate(%q) expected an error", template)
+		}
+	}
+
+	_, _, err := loadSettings([]string{"--prompt", "{{.Trainer}} > "}, func(string) string { return "" }, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "prompt from flag --prompt needs a valid prompt template") {
+		t.Errorf("expected an invalid prompt error, got %v", err)
+	}
+}
+
+func TestSettingsProfileDir(t *testing.T) {
+	s := defaultSettings()
+	s.DataDir = "/data"
+
+	if dir := s.profileDir(); dir != "/data" {
+		t.Errorf("default profile dir = %q, expected /data", dir)
+	}
+	s.Profile = "misty"
+	if dir := s.profileDir(); dir != filepath.Join("/data", "profiles", "misty") {
+		t.Errorf("profile dir = %q", dir)
+	}
+
+	for _, profile := range []string{"", "..", "a/b"} {
+		if err := s.apply(settingDefs[settingIndex(t, "profile")], profile, "flag --profile"); err == nil {
+			t.Errorf("profile %q expected an error", profile)
+		}
+	}
+}
+
+func TestFetchResource_CountsPendingRequests(t *testing.T) {
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+		settings:      defaultSettings(),
+	}
+
+	pendingDuringRequest := int32(-1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pendingDuringRequest = cfg.pendingRequests.Load()
+		w.Write([]byte(`{"count": 0, "results": []}`))
+	}))
+	defer server.Close()
+	cfg.settings.APIBaseURL = server.URL
+
+	changes := 0
+	cfg.onRequestsChanged = func() { changes++ }
+
+	if _, err := getRegions(cfg); err != nil {
+		t.Fatalf("getRegions returned unexpected error: %v", err)
+	}
+	if pendingDuringRequest != 1 || cfg.pendingRequests.Load() != 0 {
+		t.Errorf("pending requests = %d during and %d after the request", pendingDuringRequest, cfg.pendingRequests.Load())
+	}
+	if changes != 2 {
+		t.Errorf("expected 2 change notifications, got %d", changes)
+	}
+}
+
+func TestReplPrompt_RedrawsOnlyWhileEditing(t *testing.T) {
+	cfg, _, _ := newTestConfig()
+	cfg.rollPercent = func() int { return 1 }
+
+	var running atomic.Bool
+	redraws := 0
+	prompt := &replPrompt{
+		cfg:       cfg,
+		setPrompt: func(string) {},
+		refresh: func() {
+			if running.Load() {
+				t.Error("prompt redrawn while a command was running")
+			}
+			redraws++
+		},
+	}
+	cfg.onRequestsChanged = prompt.requestsChanged
+
+	// Requests finishing in the background, as tab completion's prefetch does
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 1000 {
+			prompt.requestsChanged()
+		}
+	}()
+
+	for _, line := range []string{"catch pikachu", "explore pallet-town-area", "pokedex"} {
+		prompt.edit()
+		prompt.run(func() {
+			running.Store(true)
+			defer running.Store(false)
+			if err := executeInput(cfg, line); err != nil {
+				t.Errorf("executeInput(%q) returned unexpected error: %v", line, err)
+			}
+		})
+	}
+	<-done
+
+	prompt.edit()
+	before := redraws
+	prompt.requestsChanged()
+	if redraws != before+1 {
+		t.Errorf("expected a redraw while editing, got %d", redraws-before)
+	}
+}
+
+// settingIndex returns the index of the setting with the given key.
+func settingIndex(t *testing.T, key string) int {
+	t.Helper()
+	for i, def := range settingDefs {
+		if def.key == key {
+			return i
+		}
+	}
+	t.Fatalf("no setting %q", key)
+	return -1
+}
+
+func TestStatBar(t *testing.T) {
+	cases := []struct {
+		value    int
+		maxValue int
+		width    int
+		expected string
+	}{
+		{value: 90, maxValue: 90, width: 4, expected: "████"},
+		{value: 45, maxValue: 90, width: 4, expected: "██"},
+		{value: 35, maxValue: 90, width: 4, expected: "█▌"},
+		{value: 1, maxValue: 255, width: 4, expected: ""},
+		{value: 0, maxValue: 90, width: 4, expected: ""},
+		{value: 120, maxValue: 90, width: 4, expected: "████"},
+	}
+
+	for _, c := range cases {
+		actual := statBar(c.value, c.maxValue, c.width)
+		if actual != c.expected {
+			t.Errorf("statBar(%d, %d, %d) = %q, expected %q", c.value, c.maxValue, c.width, actual, c.expected)
+		}
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	cfg := &config{settings: defaultSettings(), out: &strings.Builder{}}
+
+	cfg.settings.Color = colorAuto
+	if cfg.useColor() {
+		t.Error("expected no colors when output is not a terminal")
+	}
+
+	cfg.settings.Color = colorAlways
+	if !cfg.useColor() {
+		t.Error("expected colors when the color setting is always")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	cfg.settings.Color = colorAuto
+	cfg.out = os.Stdout
+	if cfg.useColor() {
+		t.Error("expected NO_COLOR to turn colors off")
+	}
+}
+
+func TestPaintType(t *testing.T) {
+	t.Setenv("COLORTERM", "truecolor")
+	if actual := paintType("fire"); actual != "\x1b[1;38;2;238;129;48mfire\x1b[0m" {
+		t.Errorf("paintType(fire) = %q", actual)
+	}
+	t.Setenv("COLORTERM", "")
+	if actual := paintType("fire"); actual != "\x1b[1;38;5;209mfire\x1b[0m" {
+		t.Errorf("expected the nearest 256 color without truecolor, got %q", actual)
+	}
+	if actual := paintType("stellar"); actual != "stellar" {
+		t.Errorf("expected types without a color to stay plain, got %q", actual)
+	}
+}
+
+func TestRenderSprite(t *testing.T) {
+	png, err := os.ReadFile(filepath.Join("internal", "sprite", "testdata", "tiny.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _, _ := newTestConfig()
+	cfg.settings = defaultSettings()
+	cfg.pokeapiClient.Add(pikachuSpriteURL, png)
+	pokemon := Pokemon{Name: "pikachu"}
+	pokemon.Sprites.FrontDefault = pikachuSpriteURL
+
+	cases := []struct {
+		color     colorMode
+		colorTerm string
+		expected  string
+	}{
+		{color: colorNever, colorTerm: "truecolor", expected: "%+\n"},
+		{color: colorAlways, colorTerm: "", expected: "\x1b[38;5;196;48;5;21m"},
+		{color: colorAlways, colorTerm: "truecolor", expected: "\x1b[38;2;255;0;0;48;2;0;0;255m"},
+	}
+	for _, c := range cases {
+		cfg.settings.Color = c.color
+		t.Setenv("COLORTERM", c.colorTerm)
+
+		actual, err := renderSprite(cfg, pokemon)
+		if err != nil {
+			t.Fatalf("renderSprite returned unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(actual, c.expected) {
+			t.Errorf("renderSprite with color %s and COLORTERM %q = %q, expected it to start with %q", c.color, c.colorTerm, actual, c.expected)
+		}
+	}
+
+	if _, err := renderSprite(cfg, Pokemon{Name: "missingno"}); err == nil || err.Error() != "missingno has no sprite" {
+		t.Errorf("expected a missing sprite error, got %v", err)
+	}
+}
+
+func TestCleanFlavorText(t *testing.T) {
+	cases := map[string]string{
+		"When several of\nthese POKéMON\ngather, their\felectricity": "When several of these POKéMON gather, their electricity",
+		"A strange seed was\nplanted on its\nback at birth.":         "A strange seed was planted on its back at birth.",
+		"It can gen\u00ad\nerate electricity":                        "It can generate electricity",
+	}
+	for input, expected := range cases {
+		if actual := cleanFlavorText(input); actual != expected {
+			t.Errorf("cleanFlavorText(%q) = %q, expected %q", input, actual, expected)
+		}
+	}
+}
+
+func TestSelectFlavorText(t *testing.T) {
+	entry := func(text, language, version string) flavorTextEntry {
+		return flavorTextEntry{
+			FlavorText: text,
+			Language:   namedAPIResource{Name: language},
+			Version:    namedAPIResource{Name: version},
+		}
+	}
+	entries := []flavorTextEntry{
+		entry("red en", "en", "red"),
+		entry("red ja", "ja", "red"),
+		entry("sword en", "en", "sword"),
+	}
+
+	cases := []struct {
+		language string
+		version  string
+		expected string
+		found    bool
+	}{
+		{language: "en", version: "", expected: "sword en", found: true},
+		{language: "en", version: "red", expected: "red en", found: true},
+		{language: "ja", version: "", expected: "red ja", found: true},
+		{language: "fr", version: "sword", expected: "sword en", found: true},
+		// A version without an entry falls back to the latest one
+		{language: "en", version: "gold", expected: "sword en", found: true},
+		{language: "ja", version: "gold", expected: "red ja", found: true},
+	}
+	for _, c := range cases {
+		actual, found := selectFlavorText(entries, c.language, c.version)
+		if found != c.found || actual.FlavorText != c.expected {
+			t.Errorf("selectFlavorText(%q, %q) = %q, %v, expected %q, %v", c.language, c.version, actual.FlavorText, found, c.expected, c.found)
+		}
+	}
+
+	if _, found := selectFlavorText(entries[1:2], "fr", "red"); found {
+		t.Error("expected no entry when neither the language nor English has one")
+	}
+}
+
+func TestGetSpeciesOf_FollowsPokemonForms(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pokemon/deoxys-attack":
+			w.Write([]byte(`{"name": "deoxys-attack", "species": {"name": "deoxys", "url": ""}}`))
+		case "/pokemon-species/deoxys":
+			w.Write([]byte(`{"name": "deoxys", "is_mythical": true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config{
+		pokeapiClient: pokecache.NewCache(5 * time.Minute),
+		settings:      defaultSettings(),
+	}
+	cfg.settings.APIBaseURL = server.URL
+
+	species, err := getSpeciesOf(cfg, "deoxys-attack")
+	if err != nil {
+		t.Fatalf("getSpeciesOf returned unexpected error: %v", err)
+	}
+	if species.Name != "deoxys" || !species.IsMythical {
+		t.Errorf("expected the deoxys species, got %+v", species)
+	}
+
+	if _, err := getSpeciesOf(cfg, "missingno"); err == nil || !strings.Contains(err.Error(), `pokemon "missingno" not found`) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestEvolvesInto(t *testing.T) {
+	cfg, _, _ := newTestConfig()
+
+	edges, err := evolvesInto(cfg, "pikachu")
+	if err != nil {
+		t.Fatalf("evolvesInto returned unexpected error: %v", err)
+	}
+
+	expected := []string{"raichu: use thunder-stone", "raichu-alola: use thunder-stone, at alola"}
+	if len(edges) != len(expected) {
+		t.Fatalf("expected %d evolutions, got %d", len(expected), len(edges))
+	}
+	for i, edge := range edges {
+		if actual := edge.To + ": " + evolution.Describe(edge.Details); actual != expected[i] {
+			t.Errorf("evolution %d = %q, expected %q", i, actual, expected[i])
+		}
+	}
+
+	if _, err := evolvesInto(cfg, "missingno"); err == nil {
+		t.Error("expected an error for an unknown Pokemon")
+	}
+}
+
+// eeveeChain is a cut-down copy of Eevee's evolution chain.
+const eeveeChain = `{
+	"id": 67,
+	"chain": {
+		"species": {"name": "eevee"},
+		"evolution_details": [],
+		"evolves_to": [
+			{
+				"species": {"name": "vaporeon"},
+				"evolution_details": [{"trigger": {"name": "use-item"}, "item": {"name": "water-stone"}, "time_of_day": ""}],
+				"evolves_to": []
+			},
+			{
+				"species": {"name": "espeon"},
+				"evolution_details": [{"trigger": {"name": "level-up"}, "min_happiness": 160, "time_of_day": "day"}],
+				"evolves_to": []
+			}
+		]
+	}
+}`
+
+func newEeveeConfig(t *testing.T, friendship int, hour int) *config {
+	t.Helper()
+
+	cfg, _, _ := newTestConfig()
+	cfg.pokeapiClient.Add(pokeapiBaseURL+"/pokemon-species/eevee", []byte(`{"name": "eevee", "evolution_chain": {"url": "`+pokeapiBaseURL+`/evolution-chain/67/"}}`))
+	cfg.pokeapiClient.Add(pokeapiBaseURL+"/evolution-chain/67/", []byte(eeveeChain))
+	cfg.pokeapiClient.Add(pokeapiBaseURL+"/pokemon/vaporeon", []byte(`{"name": "vaporeon", "species": {"name": "vaporeon"}}`))
+	cfg.pokeapiClient.Add(pokeapiBaseURL+"/pokemon/espeon", []byte(`{"name": "espeon", "species": {"name": "espeon"}}`))
+	cfg.now = func() time.Time { return time.Date(2024, 5, 1, hour, 0, 0, 0, time.UTC) }
+	cfg.caughtPokemon["eevee"] = ownedPokemon{
+		Pokemon:    Pokemon{Name: "eevee", Species: namedAPIResource{Name: "eevee"}},
+		Nickname:   "Fluffy",
+		Level:      20,
+		Friendship: friendship,
+		CaughtArea: "route-4",
+	}
+	return cfg
+}
+
+func TestCommandEvolve_PromptsForChoice(t *testing.T) {
+	cfg := newEeveeConfig(t, 200, 12)
+	var asked []string
+	cfg.choose = func(question string, options []string) (int, error) {
+		asked = options
+		return 1, nil
+	}
+
+	result, err := commandEvolve(cfg, commandFlags{"item": "water-stone"}, "fluffy")
+	if err != nil {
+		t.Fatalf("commandEvolve returned unexpected error: %v", err)
+	}
+	if strings.Join(asked, ",") != "vaporeon,espeon" {
+		t.Errorf("expected a choice between vaporeon and espeon, got %v", asked)
+	}
+	if evolved, ok := result.(evolveResult); !ok || evolved.Into != "espeon" {
+		t.Errorf("expected eevee to evolve into espeon, got %+v", result)
+	}
+
+	espeon, ok := cfg.caughtPokemon["espeon"]
+	if !ok {
+		t.Fatal("expected espeon to replace eevee among the caught Pokemon")
+	}
+	if _, ok := cfg.caughtPokemon["eevee"]; ok {
+		t.Error("expected eevee to be gone after evolving")
+	}
+	if espeon.Nickname != "Fluffy" || espeon.Level != 20 || espeon.CaughtArea != "route-4" {
+		t.Errorf("expected the nickname, level and catch area to be kept, got %+v", espeon)
+	}
+	if len(espeon.History) != 1 || espeon.History[0] != "evolved from eevee into espeon at level 20" {
+		t.Errorf("unexpected history %q", espeon.History)
+	}
+}
+
+func TestCommandEvolve_ChoiceNeedsInto(t *testing.T) {
+	cfg := newEeveeConfig(t, 200, 12)
+
+	_, err := commandEvolve(cfg, commandFlags{"item": "water-stone"}, "eevee")
+	expected := "Fluffy can evolve into vaporeon or espeon, choose one with --into"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+
+	result, err := commandEvolve(cfg, commandFlags{"item": "water-stone", "into": "vaporeon"}, "eevee")
+	if err != nil {
+		t.Fatalf("commandEvolve returned unexpected error: %v", err)
+	}
+	if evolved, ok := result.(evolveResult); !ok || evolved.Into != "vaporeon" {
+		t.Errorf("expected eevee to evolve into vaporeon, got %+v", result)
+	}
+}
+
+func TestCommandEvolve_AlreadyCaught(t *testing.T) {
+	cfg := newEeveeConfig(t, 200, 12)
+	vaporeon := ownedPokemon{Pokemon: Pokemon{Name: "vaporeon"}, Nickname: "Splash", Level: 40, HeldItem: "mystic-water"}
+	cfg.caughtPokemon["vaporeon"] = vaporeon
+
+	_, err := commandEvolve(cfg, commandFlags{"item": "water-stone", "into": "vaporeon"}, "eevee")
+	expected := "Fluffy cannot evolve into vaporeon, you already have Splash"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+
+	if _, ok := cfg.caughtPokemon["eevee"]; !ok {
+		t.Error("expected eevee to stay unevolved")
+	}
+	if got := cfg.caughtPokemon["vaporeon"]; got.Nickname != "Splash" || got.Level != 40 || got.HeldItem != "mystic-water" {
+		t.Errorf("expected the caught vaporeon to be kept, got %+v", got)
+	}
+}
+
+func TestCommandEvolve_NotReady(t *testing.T) {
+	cfg := newEeveeConfig(t, 200, 22)
+
+	result, err := commandEvolve(cfg, nil, "eevee")
+	if err != nil {
+		t.Fatalf("commandEvolve returned unexpected error: %v", err)
+	}
+	expected := "Fluffy is not ready to evolve\n  vaporeon needs use water-stone\n  espeon needs during the day"
+	if message, ok := result.(messageResult); !ok || message.Message != expected {
+		t.Errorf("expected message %q, got %+v", expected, result)
+	}
+	if _, ok := cfg.caughtPokemon["eevee"]; !ok {
+		t.Error("expected eevee to stay unevolved")
+	}
+
+	if _, err := commandEvolve(cfg, commandFlags{"into": "flareon"}, "eevee"); err == nil || err.Error() != "Fluffy cannot evolve into flareon" {
+		t.Errorf("expected an error for an evolution not in the chain, got %v", err)
+	}
+}
+
+func TestCommandTrain_Friendship(t *testing.T) {
+	cfg, _, _ := newTestConfig()
+	cfg.caughtPokemon["pidgey"] = ownedPokemon{Pokemon: Pokemon{Name: "pidgey"}, Level: 98, Friendship: 95}
+
+	if _, err := commandTrain(cfg, commandFlags{"levels": "5"}, "pidgey"); err != nil {
+		t.Fatalf("commandTrain returned unexpected error: %v", err)
+	}
+	pidgey := cfg.caughtPokemon["pidgey"]
+	if pidgey.Level != maxLevel || pidgey.Friendship != 95+5+3 {
+		t.Errorf("expected level %d and friendship 103, got %d and %d", maxLevel, pidgey.Level, pidgey.Friendship)
+	}
+
+	if _, err := commandTrain(cfg, commandFlags{"levels": "0"}, "pidgey"); err == nil {
+		t.Error("expected an error for --levels 0")
+	}
+}
+
+func TestParseChoice(t *testing.T) {
+	options := []string{"vaporeon", "espeon"}
+
+	cases := map[string]int{"1": 0, " 2 ": 1, "Espeon": 1}
+	for answer, expected := range cases {
+		actual, err := parseChoice(answer, options)
+		if err != nil || actual != expected {
+			t.Errorf("parseChoice(%q) = %d, %v, expected %d", answer, actual, err, expected)
+		}
+	}
+
+	for _, answer := range []string{"0", "3", "flareon", ""} {
+		if _, err := parseChoice(answer, options); err == nil {
+			t.Errorf("parseChoice(%q) succeeded, expected an error", answer)
+		}
+	}
+}
+
+func TestGenerationName(t *testing.T) {
+	cases := map[string]string{
+		"1":              "generation-i",
+		"4":              "generation-iv",
+		"IX":             "generation-ix",
+		"generation-iii": "generation-iii",
+	}
+	for input, expected := range cases {
+		actual, err := generationName(input)
+		if err != nil || actual != expected {
+			t.Errorf("generationName(%q) = %q, %v, expected %q", input, actual, err, expected)
+		}
+	}
+
+	if _, err := generationName("10"); err == nil {
+		t.Error("expected an error for generation 10")
+	}
+}
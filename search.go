@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/see-why/Pokedex/internal/fuzzy"
+)
+
+// maxSuggestions is how many "did you mean" names are offered at most.
+const maxSuggestions = 3
+
+type searchResult struct {
+	Pokemon       []string `json:"pokemon"`
+	LocationAreas []string `json:"location_areas"`
+}
+
+func (r searchResult) writeText(w io.Writer) {
+	writeSearchMatches(w, "Pokemon", r.Pokemon)
+	writeSearchMatches(w, "Location areas", r.LocationAreas)
+}
+
+func (r searchResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, name := range r.Pokemon {
+		rows = append(rows, []string{"pokemon", name})
+	}
+	for _, name := range r.LocationAreas {
+		rows = append(rows, []string{"location-area", name})
+	}
+	return []string{"kind", "name"}, rows
+}
+
+func commandSearch(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide a search term")
+	}
+
+	term := args[0]
+
+	pokemonNames, err := getNameIndex(cfg, "pokemon")
+	if err != nil {
+		return nil, err
+	}
+	areaNames, err := getNameIndex(cfg, "location-area")
+	if err != nil {
+		return nil, err
+	}
+
+	return searchResult{
+		Pokemon:       fuzzy.Search(term, pokemonNames),
+		LocationAreas: fuzzy.Search(term, areaNames),
+	}, nil
+}
+
+func writeSearchMatches(w io.Writer, heading string, matches []string) {
+	fmt.Fprintf(w, "%s:\n", heading)
+	if len(matches) == 0 {
+		fmt.Fprintln(w, " (No matches)")
+		return
+	}
+	for _, match := range matches {
+		fmt.Fprintf(w, " - %s\n", match)
+	}
+}
+
+// notFoundError reports that name is not a known resource of the given
+// kind, suggesting the closest names from the resource's name index.
+func notFoundError(cfg *config, resource, name string) error {
+	msg := fmt.Sprintf("%s %q not found", strings.ReplaceAll(resource, "-", " "), name)
+
+	names, err := getNameIndex(cfg, resource)
+	if err != nil {
+		return errors.New(msg)
+	}
+	return withSuggestions(msg, fuzzy.Closest(name, names, maxSuggestions))
+}
+
+func withSuggestions(msg string, suggestions []string) error {
+	if len(suggestions) == 0 {
+		return errors.New(msg)
+	}
+	return fmt.Errorf("%s, did you mean %s?", msg, strings.Join(suggestions, ", "))
+}
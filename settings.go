@@ -0,0 +1,457 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/see-why/Pokedex/internal/fuzzy"
+)
+
+// settings are the user-configurable values, resolved from defaults, the
+// config file, POKEDEX_* environment variables and command-line flags, with
+// each later source overriding the earlier ones.
+type settings struct {
+	CacheInterval     time.Duration
+	APIBaseURL        string
+	Prompt            string
+	CatchBaseChance   int
+	CatchMinChance    int
+	CatchExpDivisor   int
+	Language          string
+	GameVersion       string
+	Output            outputFormat
+	Color             colorMode
+	DataDir           string
+	Profile           string
+	HistorySize       int
+	ConfigPath        string
+	configFileMissing bool
+	// sources records where each setting's value came from, keyed by
+	// setting key. Settings left at their default are absent.
+	sources map[string]string
+}
+
+// defaultProfile is the profile used unless another one is chosen. Its files
+// live directly in the data directory.
+const defaultProfile = "default"
+
+type colorMode string
+
+const (
+	colorAuto   colorMode = "auto"
+	colorAlways colorMode = "always"
+	colorNever  colorMode = "never"
+)
+
+// settingDef describes one setting: its key in the config file, which also
+// names its flag and POKEDEX_* environment variable, and how to read and
+// print its value.
+type settingDef struct {
+	key         string
+	description string
+	set         func(s *settings, value string) error
+	get         func(s *settings) string
+}
+
+var settingDefs = []settingDef{
+	{
+		key:         "cache_interval",
+		description: "how long API responses are cached, e.g. 5m or 1h",
+		set: func(s *settings, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("needs a positive duration such as 5m, got %q", value)
+			}
+			s.CacheInterval = d
+			return nil
+		},
+		get: func(s *settings) string { return s.CacheInterval.String() },
+	},
+	{
+		key:         "api_base_url",
+		description: "base URL of the PokeAPI server",
+		set: func(s *settings, value string) error {
+			if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+				return fmt.Errorf("needs an http or https URL, got %q", value)
+			}
+			s.APIBaseURL = strings.TrimSuffix(value, "/")
+			return nil
+		},
+		get: func(s *settings) string { return s.APIBaseURL },
+	},
+	{
+		key:         "prompt",
+		description: "prompt template shown by the interactive shell",
+		set: func(s *settings, value string) error {
+			if err := validatePromptTemplate(value); err != nil {
+				return fmt.Errorf("needs a valid prompt template: %w", err)
+			}
+			s.Prompt = value
+			return nil
+		},
+		get: func(s *settings) string { return strconv.Quote(s.Prompt) },
+	},
+	{
+		key:         "catch_base_chance",
+		description: "catch chance in percent for a Pokemon with no base experience",
+		set:         intSetting(func(s *settings) *int { return &s.CatchBaseChance }, 1, 100),
+		get:         func(s *settings) string { return strconv.Itoa(s.CatchBaseChance) },
+	},
+	{
+		key:         "catch_min_chance",
+		description: "lowest catch chance in percent, however experienced the Pokemon",
+		set:         intSetting(func(s *settings) *int { return &s.CatchMinChance }, 1, 100),
+		get:         func(s *settings) string { return strconv.Itoa(s.CatchMinChance) },
+	},
+	{
+		key:         "catch_exp_divisor",
+		description: "base experience that lowers the catch chance by one percent",
+		set:         intSetting(func(s *settings) *int { return &s.CatchExpDivisor }, 1, 10000),
+		get:         func(s *settings) string { return strconv.Itoa(s.CatchExpDivisor) },
+	},
+	{
+		key:         "language",
+		description: "language of flavor text and genus names, e.g. en or fr",
+		set: func(s *settings, value string) error {
+			if value == "" || strings.ContainsAny(value, " /") {
+				return fmt.Errorf("needs a PokeAPI language name such as en, got %q", value)
+			}
+			s.Language = strings.ToLower(value)
+			return nil
+		},
+		get: func(s *settings) string { return s.Language },
+	},
+	{
+		key:         "game_version",
+		description: "game whose Pokedex entries are shown, e.g. red; the latest when empty",
+		set: func(s *settings, value string) error {
+			s.GameVersion = apiName(value)
+			return nil
+		},
+		get: func(s *settings) string { return strconv.Quote(s.GameVersion) },
+	},
+	{
+		key:         "output",
+		description: "output format: json, yaml, table or text",
+		set: func(s *settings, value string) error {
+			format, err := parseOutputFormat(value)
+			if err != nil {
+				return err
+			}
+			s.Output = format
+			return nil
+		},
+		get: func(s *settings) string { return string(s.Output) },
+	},
+	{
+		key:         "color",
+		description: "when to use colors: auto, always or never",
+		set: func(s *settings, value string) error {
+			switch mode := colorMode(strings.ToLower(value)); mode {
+			case colorAuto, colorAlways, colorNever:
+				s.Color = mode
+				return nil
+			}
+			return fmt.Errorf("needs auto, always or never, got %q", value)
+		},
+		get: func(s *settings) string { return string(s.Color) },
+	},
+	{
+		key:         "data_dir",
+		description: "directory for history and other saved data",
+		set: func(s *settings, value string) error {
+			s.DataDir = value
+			return nil
+		},
+		get: func(s *settings) string { return s.DataDir },
+	},
+	{
+		key:         "profile",
+		description: "trainer profile, which keeps its own history",
+		set: func(s *settings, value string) error {
+			if value == "" || value == "." || value == ".." || strings.ContainsAny(value, `/\`) {
+				return fmt.Errorf("needs a name without slashes, got %q", value)
+			}
+			s.Profile = value
+			return nil
+		},
+		get: func(s *settings) string { return s.Profile },
+	},
+	{
+		key:         "history_size",
+		description: "number of commands kept in the history",
+		set:         intSetting(func(s *settings) *int { return &s.HistorySize }, 1, 1000000),
+		get:         func(s *settings) string { return strconv.Itoa(s.HistorySize) },
+	},
+}
+
+// intSetting returns a setter for the integer field returned by field that
+// accepts values from lo to hi.
+func intSetting(field func(s *settings) *int, lo, hi int) func(s *settings, value string) error {
+	return func(s *settings, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < lo || n > hi {
+			return fmt.Errorf("needs a whole number from %d to %d, got %q", lo, hi, value)
+		}
+		*field(s) = n
+		return nil
+	}
+}
+
+func defaultSettings() *settings {
+	s := &settings{
+		CacheInterval:   5 * time.Minute,
+		APIBaseURL:      pokeapiBaseURL,
+		Prompt:          "Pokedex > ",
+		CatchBaseChance: 50,
+		CatchMinChance:  5,
+		CatchExpDivisor: 10,
+		Language:        "en",
+		Output:          outputText,
+		Color:           colorAuto,
+		Profile:         defaultProfile,
+		HistorySize:     defaultHistoryLimit,
+		sources:         map[string]string{},
+	}
+	if dir, err := dataDir(); err == nil {
+		s.DataDir = dir
+	}
+	return s
+}
+
+// loadSettings resolves the settings for a run from the command-line
+// arguments (without the program name) and the environment, and returns the
+// arguments left after the flags. The config file is read from --config,
+// POKEDEX_CONFIG or config.json in the config directory, in that order.
+func loadSettings(args []string, getenv func(string) string, errOut io.Writer) (*settings, []string, error) {
+	s := defaultSettings()
+
+	// Parse errors are returned to the caller to report, so the flag set
+	// itself only prints the usage when asked for it
+	fs := flag.NewFlagSet("pokedex", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {
+		fmt.Fprintln(errOut, "Usage: pokedex [flags] [command [arguments]]")
+		fmt.Fprintln(errOut, "       pokedex [flags] run <script>")
+		fs.SetOutput(errOut)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "path of the JSON config file")
+	byFlag := map[string]settingDef{}
+	for _, def := range settingDefs {
+		fs.String(settingFlagName(def.key), "", def.description)
+		byFlag[settingFlagName(def.key)] = def
+	}
+	fs.String("o", "", "shorthand for --output")
+	byFlag["o"] = byFlag["output"]
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	// The config file is optional unless one was asked for by name
+	explicit := true
+	s.ConfigPath = *configPath
+	if s.ConfigPath == "" {
+		s.ConfigPath = getenv("POKEDEX_CONFIG")
+	}
+	if s.ConfigPath == "" {
+		explicit = false
+		if dir, err := configDir(); err == nil {
+			s.ConfigPath = filepath.Join(dir, "config.json")
+		}
+	}
+	if s.ConfigPath != "" {
+		err := s.readFile(s.ConfigPath)
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			s.configFileMissing = true
+		} else if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, def := range settingDefs {
+		name := settingEnvName(def.key)
+		if value := getenv(name); value != "" {
+			if err := s.apply(def, value, "env "+name); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		def, ok := byFlag[f.Name]
+		if !ok || flagErr != nil {
+			return
+		}
+		source := "flag --" + f.Name
+		if f.Name == "o" {
+			source = "flag -o"
+		}
+		flagErr = s.apply(def, f.Value.String(), source)
+	})
+	if flagErr != nil {
+		return nil, nil, flagErr
+	}
+
+	return s, fs.Args(), nil
+}
+
+// readFile applies the settings in the JSON config file at path. Values may
+// be written as JSON strings or as plain numbers. Only JSON is read, so files
+// named for another format are refused rather than failing to parse.
+func (s *settings) readFile(path string) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml", ".yaml", ".yml":
+		return fmt.Errorf("reading %s: config files are JSON, %s is not supported", path, ext)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	keys := make([]string, 0, len(settingDefs))
+	for _, def := range settingDefs {
+		keys = append(keys, def.key)
+	}
+
+	for _, def := range settingDefs {
+		raw, ok := values[def.key]
+		if !ok {
+			continue
+		}
+		delete(values, def.key)
+
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			value = string(raw)
+		}
+		if err := s.apply(def, value, "config file"); err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+
+	// Anything left over is a setting we do not know
+	for key := range values {
+		err := withSuggestions(fmt.Sprintf("unknown setting %q", key), fuzzy.Closest(key, keys, maxSuggestions))
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (s *settings) apply(def settingDef, value, source string) error {
+	if err := def.set(s, value); err != nil {
+		return fmt.Errorf("%s from %s %w", def.key, source, err)
+	}
+	s.sources[def.key] = source
+	return nil
+}
+
+// settingFlagName turns a setting key such as cache_interval into its flag
+// name, cache-interval.
+func settingFlagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
+
+// settingEnvName turns a setting key such as cache_interval into its
+// environment variable, POKEDEX_CACHE_INTERVAL.
+func settingEnvName(key string) string {
+	return "POKEDEX_" + strings.ToUpper(key)
+}
+
+// profileDir returns the directory for the active profile's files, or "" when
+// there is no data directory.
+func (s *settings) profileDir() string {
+	if s.DataDir == "" || s.Profile == defaultProfile {
+		return s.DataDir
+	}
+	return filepath.Join(s.DataDir, "profiles", s.Profile)
+}
+
+// currentSettings returns the settings of this run, or the defaults when none
+// were loaded.
+func (cfg *config) currentSettings() *settings {
+	if cfg.settings == nil {
+		cfg.settings = defaultSettings()
+	}
+	return cfg.settings
+}
+
+type configResult struct {
+	ConfigFile string         `json:"config_file"`
+	Settings   []settingValue `json:"settings"`
+}
+
+type settingValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+func (r configResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Config file: %s\n", r.ConfigFile)
+
+	keyWidth, valueWidth := 0, 0
+	for _, setting := range r.Settings {
+		keyWidth = max(keyWidth, len(setting.Key))
+		valueWidth = max(valueWidth, len(setting.Value))
+	}
+	for _, setting := range r.Settings {
+		fmt.Fprintf(w, "  %-*s  %-*s  (%s)\n", keyWidth, setting.Key, valueWidth, setting.Value, setting.Source)
+	}
+}
+
+func (r configResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, setting := range r.Settings {
+		rows = append(rows, []string{setting.Key, setting.Value, setting.Source})
+	}
+	return []string{"setting", "value", "source"}, rows
+}
+
+func commandConfig(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	s := cfg.currentSettings()
+
+	result := configResult{ConfigFile: s.ConfigPath, Settings: []settingValue{}}
+	if s.ConfigPath == "" {
+		result.ConfigFile = "(none)"
+	} else if s.configFileMissing {
+		result.ConfigFile += " (not found)"
+	}
+
+	for _, def := range settingDefs {
+		if len(args) > 0 && def.key != args[0] {
+			continue
+		}
+		source, ok := s.sources[def.key]
+		if !ok {
+			source = "default"
+		}
+		result.Settings = append(result.Settings, settingValue{Key: def.key, Value: def.get(s), Source: source})
+	}
+
+	if len(result.Settings) == 0 {
+		keys := make([]string, 0, len(settingDefs))
+		for _, def := range settingDefs {
+			keys = append(keys, def.key)
+		}
+		return nil, withSuggestions(fmt.Sprintf("unknown setting %q", args[0]), fuzzy.Closest(args[0], keys, maxSuggestions))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type speciesResult struct {
+	Name          string   `json:"name"`
+	Genus         string   `json:"genus"`
+	Generation    string   `json:"generation"`
+	Habitat       string   `json:"habitat,omitempty"`
+	Color         string   `json:"color"`
+	Shape         string   `json:"shape,omitempty"`
+	Legendary     bool     `json:"legendary"`
+	Mythical      bool     `json:"mythical"`
+	EggGroups     []string `json:"egg_groups"`
+	FlavorText    string   `json:"flavor_text,omitempty"`
+	FlavorVersion string   `json:"flavor_version,omitempty"`
+}
+
+// newSpeciesResult picks the genus and flavor text of species in language,
+// and the flavor text of the given game version, or of the latest game when
+// version is empty. English is used when language has no text.
+func newSpeciesResult(species pokemonSpeciesResp, language, version string) speciesResult {
+	result := speciesResult{
+		Name:       species.Name,
+		Genus:      speciesGenus(species, language),
+		Generation: species.Generation.Name,
+		Color:      species.Color.Name,
+		Legendary:  species.IsLegendary,
+		Mythical:   species.IsMythical,
+		EggGroups:  []string{},
+	}
+	if species.Habitat != nil {
+		result.Habitat = species.Habitat.Name
+	}
+	if species.Shape != nil {
+		result.Shape = species.Shape.Name
+	}
+	for _, group := range species.EggGroups {
+		result.EggGroups = append(result.EggGroups, group.Name)
+	}
+
+	if entry, ok := selectFlavorText(species.FlavorTextEntries, language, version); ok {
+		result.FlavorText = cleanFlavorText(entry.FlavorText)
+		result.FlavorVersion = entry.Version.Name
+	}
+
+	return result
+}
+
+func speciesGenus(species pokemonSpeciesResp, language string) string {
+	fallback := ""
+	for _, genus := range species.Genera {
+		switch genus.Language.Name {
+		case language:
+			return genus.Genus
+		case "en":
+			fallback = genus.Genus
+		}
+	}
+	return fallback
+}
+
+// selectFlavorText returns the entry for version in language, or the last
+// entry in language when version is empty or has no entry. PokeAPI lists
+// entries oldest game first, so the last one is the most recent. English
+// stands in when language has no entries.
+func selectFlavorText(entries []flavorTextEntry, language, version string) (flavorTextEntry, bool) {
+	versions := []string{""}
+	if version != "" {
+		versions = []string{version, ""}
+	}
+
+	for _, v := range versions {
+		for _, lang := range []string{language, "en"} {
+			var match flavorTextEntry
+			found := false
+			for _, entry := range entries {
+				if entry.Language.Name != lang {
+					continue
+				}
+				if v == "" || entry.Version.Name == v {
+					match = entry
+					found = true
+				}
+			}
+			if found {
+				return match, true
+			}
+		}
+	}
+	return flavorTextEntry{}, false
+}
+
+// cleanFlavorText joins the lines of a flavor text entry, which keeps the
+// line breaks, page breaks and soft hyphens of the game's text box.
+func cleanFlavorText(text string) string {
+	text = strings.NewReplacer("\u00ad\n", "", "\u00ad", "", "\f", " ").Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func (r speciesResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s - %s\n", r.Name, r.Genus)
+	r.writeDetails(w)
+}
+
+// writeDetails writes everything but the name, for use below other output
+// about the same Pokemon.
+func (r speciesResult) writeDetails(w io.Writer) {
+	fmt.Fprintf(w, "Generation: %s\n", r.Generation)
+	if r.Habitat != "" {
+		fmt.Fprintf(w, "Habitat: %s\n", r.Habitat)
+	}
+	fmt.Fprintf(w, "Color: %s\n", r.Color)
+	if r.Shape != "" {
+		fmt.Fprintf(w, "Shape: %s\n", r.Shape)
+	}
+	if len(r.EggGroups) > 0 {
+		fmt.Fprintf(w, "Egg groups: %s\n", strings.Join(r.EggGroups, ", "))
+	}
+	if r.Legendary {
+		fmt.Fprintln(w, "Legendary Pokemon")
+	}
+	if r.Mythical {
+		fmt.Fprintln(w, "Mythical Pokemon")
+	}
+	if r.FlavorText != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%s (%s)\n", r.FlavorText, r.FlavorVersion)
+	}
+}
+
+func commandSpecies(cfg *config, flags commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide a Pokemon species name")
+	}
+
+	species, err := getSpeciesOf(cfg, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s := cfg.currentSettings()
+	language, version := s.Language, s.GameVersion
+	if flags.has("language") {
+		language = strings.ToLower(flags.get("language"))
+	}
+	if flags.has("version") {
+		version = apiName(flags.get("version"))
+	}
+
+	return newSpeciesResult(species, language, version), nil
+}
+
+// getSpeciesOf returns the species called name, or the species of the
+// Pokemon called name, so forms such as deoxys-attack find deoxys.
+func getSpeciesOf(cfg *config, name string) (pokemonSpeciesResp, error) {
+	species, err := getPokemonSpecies(cfg, name)
+	if !errors.Is(err, errNotFound) {
+		return species, err
+	}
+
+	pokemon, err := getPokemon(cfg, name)
+	if errors.Is(err, errNotFound) {
+		return pokemonSpeciesResp{}, notFoundError(cfg, "pokemon", name)
+	}
+	if err != nil {
+		return pokemonSpeciesResp{}, err
+	}
+	return getPokemonSpecies(cfg, pokemon.Species.Name)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/see-why/Pokedex/internal/typechart"
+)
+
+// getTypeChart loads the damage relations of the named types.
+func getTypeChart(cfg *config, typeNames ...string) (typechart.Chart, error) {
+	chart := typechart.Chart{}
+	for _, name := range typeNames {
+		if _, ok := chart[name]; ok {
+			continue
+		}
+
+		t, err := getType(cfg, name)
+		if errors.Is(err, errNotFound) {
+			return nil, notFoundError(cfg, "type", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		relations := t.DamageRelations
+		chart[t.Name] = typechart.Relations{
+			DoubleDamageTo:   resourceNames(relations.DoubleDamageTo),
+			HalfDamageTo:     resourceNames(relations.HalfDamageTo),
+			NoDamageTo:       resourceNames(relations.NoDamageTo),
+			DoubleDamageFrom: resourceNames(relations.DoubleDamageFrom),
+			HalfDamageFrom:   resourceNames(relations.HalfDamageFrom),
+			NoDamageFrom:     resourceNames(relations.NoDamageFrom),
+		}
+	}
+	return chart, nil
+}
+
+func resourceNames(resources []namedAPIResource) []string {
+	names := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		names = append(names, resource.Name)
+	}
+	return names
+}
+
+type typesResult struct {
+	Types []string `json:"types"`
+	// Attacking is left out for dual types, whose moves each have one type.
+	Attacking []typechart.Group `json:"attacking,omitempty"`
+	Defending []typechart.Group `json:"defending"`
+}
+
+func (r typesResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, strings.Join(r.Types, "/"))
+	if r.Attacking != nil {
+		fmt.Fprintln(w, "Attacking:")
+		writeTypeGroups(w, r.Attacking)
+	}
+	fmt.Fprintln(w, "Defending:")
+	writeTypeGroups(w, r.Defending)
+}
+
+func writeTypeGroups(w io.Writer, groups []typechart.Group) {
+	if len(groups) == 0 {
+		fmt.Fprintln(w, "  1x against every type")
+	}
+	for _, group := range groups {
+		fmt.Fprintf(w, "  %s: %s\n", typechart.Format(group.Multiplier), strings.Join(group.Types, ", "))
+	}
+}
+
+func (r typesResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, group := range r.Attacking {
+		for _, name := range group.Types {
+			rows = append(rows, []string{"attacking", name, typechart.Format(group.Multiplier)})
+		}
+	}
+	for _, group := range r.Defending {
+		for _, name := range group.Types {
+			rows = append(rows, []string{"defending", name, typechart.Format(group.Multiplier)})
+		}
+	}
+	return []string{"side", "type", "multiplier"}, rows
+}
+
+func commandTypes(cfg *config, _ commandFlags, args ...string) (commandResult, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("you must provide a type name")
+	}
+	if len(args) > 2 {
+		return nil, fmt.Errorf("a Pokemon has at most two types")
+	}
+	// The chart would apply a repeated type twice, squaring its multipliers
+	if len(args) == 2 && apiName(args[0]) == apiName(args[1]) {
+		return nil, fmt.Errorf("a Pokemon cannot have %s as both of its types", apiName(args[0]))
+	}
+
+	chart, err := getTypeChart(cfg, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := typesResult{Types: args}
+	defense, err := chart.Defense(args...)
+	if err != nil {
+		return nil, err
+	}
+	result.Defending = typechart.Groups(defense)
+
+	if len(args) == 1 {
+		offense, err := chart.Offense(args[0])
+		if err != nil {
+			return nil, err
+		}
+		result.Attacking = typechart.Groups(offense)
+	}
+
+	return result, nil
+}
+
+type matchupResult struct {
+	Pokemon  [2]matchupSide `json:"pokemon"`
+	Attacks  [2][]typeHit   `json:"attacks"`
+	Favorite string         `json:"favorite,omitempty"`
+}
+
+type matchupSide struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"`
+}
+
+// typeHit is how hard moves of one type hit the other Pokemon.
+type typeHit struct {
+	Type       string  `json:"type"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+func (r matchupResult) writeText(w io.Writer) {
+	first, second := r.Pokemon[0], r.Pokemon[1]
+	fmt.Fprintf(w, "%s (%s) vs %s (%s)\n", first.Name, strings.Join(first.Types, ", "), second.Name, strings.Join(second.Types, ", "))
+
+	for i, attacker := range r.Pokemon {
+		defender := r.Pokemon[1-i]
+		fmt.Fprintf(w, "%s attacking %s:\n", attacker.Name, defender.Name)
+		for _, hit := range r.Attacks[i] {
+			fmt.Fprintf(w, "  %s: %s\n", hit.Type, typechart.Format(hit.Multiplier))
+		}
+	}
+
+	if r.Favorite != "" {
+		fmt.Fprintf(w, "Type advantage: %s\n", r.Favorite)
+	} else {
+		fmt.Fprintln(w, "Type advantage: even")
+	}
+}
+
+func (r matchupResult) tableRows() ([]string, [][]string) {
+	rows := [][]string{}
+	for i, attacker := range r.Pokemon {
+		for _, hit := range r.Attacks[i] {
+			rows = append(rows, []string{attacker.Name, r.Pokemon[1-i].Name, hit.Type, typechart.Format(hit.Multiplier)})
+		}
+	}
+	return []string{"attacker", "defender", "type", "multiplier"}, rows
+}
+
+func commandMatchup(cfg *config, _ commandFlags, args ...string) (commandResult, error) {
+	// "vs" between the names is optional
+	if len(args) == 3 && strings.EqualFold(args[1], "vs") {
+		args = []string{args[0], args[2]}
+	}
+	if len(args) != 2 {
+		return nil, fmt.Errorf("compare two Pokemon, e.g. matchup pikachu vs pidgey")
+	}
+
+	result := matchupResult{}
+	typeNames := []string{}
+	for i, name := range args {
+		pokemon, err := findPokemon(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		side := matchupSide{Name: pokemon.displayName(), Types: []string{}}
+		for _, t := range pokemon.Types {
+			side.Types = append(side.Types, t.Type.Name)
+		}
+		result.Pokemon[i] = side
+		typeNames = append(typeNames, side.Types...)
+	}
+
+	chart, err := getTypeChart(cfg, typeNames...)
+	if err != nil {
+		return nil, err
+	}
+
+	best := [2]float64{}
+	for i, attacker := range result.Pokemon {
+		result.Attacks[i], err = typeHits(chart, attacker.Types, result.Pokemon[1-i].Types)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range result.Attacks[i] {
+			best[i] = max(best[i], hit.Multiplier)
+		}
+	}
+
+	switch {
+	case best[0] > best[1]:
+		result.Favorite = result.Pokemon[0].Name
+	case best[1] > best[0]:
+		result.Favorite = result.Pokemon[1].Name
+	}
+
+	return result, nil
+}
+
+// typeHits is how hard moves of each of the attacking types hit a Pokemon of
+// the defending types.
+func typeHits(chart typechart.Chart, attacking, defending []string) ([]typeHit, error) {
+	hits := []typeHit{}
+	for _, attackingType := range attacking {
+		multiplier, err := chart.Multiplier(attackingType, defending...)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, typeHit{Type: attackingType, Multiplier: multiplier})
+	}
+	return hits, nil
+}